@@ -1,6 +1,8 @@
 package basket
 
 import (
+	"net/http"
+
 	"github.com/pact-cdc-example/basket-service/pkg/cerr"
 )
 
@@ -9,4 +11,74 @@ import (
 const (
 	BasketNotFoundErrCode           cerr.Code = 10100
 	ProductNotHasEnoughStockErrCode cerr.Code = 10101
+	BasketCheckedOutErrCode         cerr.Code = 10102
+	ReservationsPendingErrCode      cerr.Code = 10103
+)
+
+func init() {
+	cerr.RegisterStatus(BasketNotFoundErrCode, http.StatusNotFound)
+	cerr.RegisterStatus(ProductNotHasEnoughStockErrCode, http.StatusConflict)
+	cerr.RegisterStatus(BasketCheckedOutErrCode, http.StatusConflict)
+	cerr.RegisterStatus(ReservationsPendingErrCode, http.StatusConflict)
+
+	// reservations are confirmed or expired in the background, checking out
+	// again later can succeed.
+	cerr.RegisterClass(ReservationsPendingErrCode, cerr.ClassTransient)
+}
+
+func BasketNotFound() cerr.Bag {
+	return cerr.Bag{
+		Code:    BasketNotFoundErrCode,
+		Message: "basket not found",
+	}
+}
+
+func ProductNotHasEnoughStock() cerr.Bag {
+	return cerr.Bag{
+		Code:    ProductNotHasEnoughStockErrCode,
+		Message: "Product not has enough stock.",
+	}
+}
+
+func BasketCheckedOut() cerr.Bag {
+	return cerr.Bag{
+		Code:    BasketCheckedOutErrCode,
+		Message: "Basket is already checked out.",
+	}
+}
+
+func ReservationsPending() cerr.Bag {
+	return cerr.Bag{
+		Code:    ReservationsPendingErrCode,
+		Message: "Stock reservations of the basket are in progress.",
+	}
+}
+
+// basket specific warnings
+
+const (
+	StaleProductDataWarnCode    cerr.Code = 10150
+	PriceChangedWarnCode        cerr.Code = 10151
+	UnavailableProductsWarnCode cerr.Code = 10152
 )
+
+func StaleProductData() cerr.Bag {
+	return cerr.Bag{
+		Code:    StaleProductDataWarnCode,
+		Message: "Product information could not be refreshed, showing the data saved when the products were added.",
+	}
+}
+
+func PriceChanged() cerr.Bag {
+	return cerr.Bag{
+		Code:    PriceChangedWarnCode,
+		Message: "Price of at least one product has changed since it was added to the basket.",
+	}
+}
+
+func UnavailableProducts() cerr.Bag {
+	return cerr.Bag{
+		Code:    UnavailableProductsWarnCode,
+		Message: "At least one product in the basket is no longer available.",
+	}
+}
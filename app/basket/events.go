@@ -0,0 +1,77 @@
+package basket
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/basket-service/app/basket/events"
+)
+
+// publishEvent writes event to the outbox in the transaction of repo, so it
+// is published by the outbox relay only once the change it tells about is
+// committed.
+func publishEvent(ctx context.Context, repo Repository, basketID string, event events.Event) error {
+	message, err := events.NewMessage(event)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return repo.CreateOutboxMessage(ctx, &OutboxMessage{
+		ID:       uuid.New().String(),
+		BasketID: basketID,
+		Command:  CommandPublishEvent,
+		Payload:  payload,
+		Status:   OutboxStatusPending,
+	})
+}
+
+func newBasketCreatedEvent(b *Basket) events.BasketCreated {
+	return events.BasketCreated{
+		BasketID:   b.ID,
+		UserID:     b.UserID,
+		OccurredAt: b.CreatedAt,
+	}
+}
+
+// newItemAddedEvent returns the event of p added to b, the lines of a basket
+// are read back without the time they were added at.
+func newItemAddedEvent(b *Basket, p *Product, occurredAt time.Time) events.ItemAdded {
+	event := events.ItemAdded{
+		BasketID:   b.ID,
+		UserID:     b.UserID,
+		ProductID:  p.ID,
+		Quantity:   p.Quantity,
+		OccurredAt: occurredAt,
+	}
+
+	if p.Snapshot != nil {
+		event.Price = p.Snapshot.Price
+	}
+
+	return event
+}
+
+func newCheckedOutEvent(b *Basket) events.CheckedOut {
+	event := events.CheckedOut{
+		BasketID: b.ID,
+		UserID:   b.UserID,
+		Items:    make([]events.Item, len(b.Products)),
+	}
+
+	for i, p := range b.Products {
+		event.Items[i] = events.Item{ProductID: p.ID, Quantity: p.Quantity}
+	}
+
+	if b.CheckedOutAt != nil {
+		event.CheckedOutAt = *b.CheckedOutAt
+	}
+
+	return event
+}
@@ -0,0 +1,128 @@
+//go:build consumer
+
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/pact-cdc-example/basket-service/app/basket/events"
+	"github.com/pact-foundation/pact-go/dsl"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	consumerBasketEvents  = "BasketEventsConsumer"
+	providerBasketService = "BasketService"
+)
+
+type EventsConsumerTestSuite struct {
+	suite.Suite
+	pact *dsl.Pact
+}
+
+func TestEventsConsumerTestSuite(t *testing.T) {
+	suite.Run(t, new(EventsConsumerTestSuite))
+}
+
+func (s *EventsConsumerTestSuite) SetupSuite() {
+	s.pact = &dsl.Pact{
+		Consumer:                 consumerBasketEvents,
+		Provider:                 providerBasketService,
+		DisableToolValidityCheck: true,
+		PactFileWriteMode:        "merge",
+		LogDir:                   "./pacts/logs",
+		PactDir:                  "./pacts",
+	}
+}
+
+func (s *EventsConsumerTestSuite) TearDownSuite() {
+	defer s.pact.Teardown()
+}
+
+func (s *EventsConsumerTestSuite) TestGivenBasketCreatedMessageThenItShouldBeDecoded() {
+	message := s.pact.AddMessage()
+	message.
+		ExpectsToReceive("a basket created event").
+		WithMetadata(metadata(events.BasketCreatedType)).
+		WithContent(dsl.StructMatcher{
+			"basket_id":   dsl.Like(gofakeit.UUID()),
+			"user_id":     dsl.Like(gofakeit.UUID()),
+			"occurred_at": dsl.Like(gofakeit.Date().Format(time.RFC3339)),
+		}).
+		AsType(&events.BasketCreated{})
+
+	err := s.pact.VerifyMessageConsumer(s.T(), message, s.decode(events.BasketCreatedType))
+
+	s.Nil(err)
+}
+
+func (s *EventsConsumerTestSuite) TestGivenItemAddedMessageThenItShouldBeDecoded() {
+	message := s.pact.AddMessage()
+	message.
+		ExpectsToReceive("an item added event").
+		WithMetadata(metadata(events.ItemAddedType)).
+		WithContent(dsl.StructMatcher{
+			"basket_id":   dsl.Like(gofakeit.UUID()),
+			"user_id":     dsl.Like(gofakeit.UUID()),
+			"product_id":  dsl.Like(gofakeit.UUID()),
+			"quantity":    dsl.Like(int(gofakeit.Uint8()) + 1),
+			"price":       dsl.Like(gofakeit.Price(10, 100)),
+			"occurred_at": dsl.Like(gofakeit.Date().Format(time.RFC3339)),
+		}).
+		AsType(&events.ItemAdded{})
+
+	err := s.pact.VerifyMessageConsumer(s.T(), message, s.decode(events.ItemAddedType))
+
+	s.Nil(err)
+}
+
+func (s *EventsConsumerTestSuite) TestGivenCheckedOutMessageThenItShouldBeDecoded() {
+	message := s.pact.AddMessage()
+	message.
+		ExpectsToReceive("a basket checked out event").
+		WithMetadata(metadata(events.CheckedOutType)).
+		WithContent(dsl.StructMatcher{
+			"basket_id": dsl.Like(gofakeit.UUID()),
+			"user_id":   dsl.Like(gofakeit.UUID()),
+			"items": dsl.EachLike(dsl.StructMatcher{
+				"product_id": dsl.Like(gofakeit.UUID()),
+				"quantity":   dsl.Like(int(gofakeit.Uint8()) + 1),
+			}, 1),
+			"checked_out_at": dsl.Like(gofakeit.Date().Format(time.RFC3339)),
+		}).
+		AsType(&events.CheckedOut{})
+
+	err := s.pact.VerifyMessageConsumer(s.T(), message, s.decode(events.CheckedOutType))
+
+	s.Nil(err)
+}
+
+// decode hands the message content to events.Decode the way a consumer
+// reading the messages from the broker would.
+func (s *EventsConsumerTestSuite) decode(eventType events.Type) dsl.MessageConsumer {
+	return func(m dsl.Message) error {
+		content, err := json.Marshal(m.Content)
+		if err != nil {
+			return err
+		}
+
+		event, err := events.Decode(eventType, content)
+		if err != nil {
+			return err
+		}
+
+		s.Equal(eventType, event.EventType())
+
+		return nil
+	}
+}
+
+func metadata(eventType events.Type) dsl.MapMatcher {
+	return dsl.MapMatcher{
+		events.MetadataContentType: dsl.String("application/json"),
+		events.MetadataEventType:   dsl.String(string(eventType)),
+	}
+}
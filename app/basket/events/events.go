@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Type names an event, it is sent in the event_type metadata of the message
+// which carries the event.
+type Type string
+
+const (
+	BasketCreatedType Type = "basket.created"
+	ItemAddedType     Type = "basket.item_added"
+	CheckedOutType    Type = "basket.checked_out"
+)
+
+const (
+	MetadataContentType = "contentType"
+	MetadataEventType   = "event_type"
+)
+
+type Event interface {
+	EventType() Type
+}
+
+type BasketCreated struct {
+	BasketID   string    `json:"basket_id"`
+	UserID     string    `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (BasketCreated) EventType() Type {
+	return BasketCreatedType
+}
+
+// ItemAdded is emitted for every product added to a basket, Price is the
+// price the shopper saw when adding it.
+type ItemAdded struct {
+	BasketID   string    `json:"basket_id"`
+	UserID     string    `json:"user_id"`
+	ProductID  string    `json:"product_id"`
+	Quantity   int       `json:"quantity"`
+	Price      float64   `json:"price"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ItemAdded) EventType() Type {
+	return ItemAddedType
+}
+
+type CheckedOut struct {
+	BasketID     string    `json:"basket_id"`
+	UserID       string    `json:"user_id"`
+	Items        []Item    `json:"items"`
+	CheckedOutAt time.Time `json:"checked_out_at"`
+}
+
+func (CheckedOut) EventType() Type {
+	return CheckedOutType
+}
+
+type Item struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Metadata returns the metadata of the message which carries event.
+func Metadata(event Event) map[string]string {
+	return map[string]string{
+		MetadataContentType: "application/json",
+		MetadataEventType:   string(event.EventType()),
+	}
+}
+
+// Message is an event as it is published, its content is the event encoded
+// as json.
+type Message struct {
+	Metadata map[string]string `json:"metadata"`
+	Content  json.RawMessage   `json:"content"`
+}
+
+func NewMessage(event Event) (*Message, error) {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{Metadata: Metadata(event), Content: content}, nil
+}
+
+// Decode decodes the content of a message whose event_type metadata is
+// eventType.
+func Decode(eventType Type, content []byte) (Event, error) {
+	var event Event
+	switch eventType {
+	case BasketCreatedType:
+		event = &BasketCreated{}
+	case ItemAddedType:
+		event = &ItemAdded{}
+	case CheckedOutType:
+		event = &CheckedOut{}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+
+	if err := json.Unmarshal(content, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, message *Message) error
+}
+
+type logPublisher struct {
+	logger *logrus.Logger
+}
+
+type NewLogPublisherOpts struct {
+	L *logrus.Logger
+}
+
+// NewLogPublisher returns a publisher which writes the messages to the log,
+// the service is not connected to a message broker yet.
+func NewLogPublisher(opts *NewLogPublisherOpts) Publisher {
+	return &logPublisher{logger: opts.L}
+}
+
+func (p *logPublisher) Publish(ctx context.Context, message *Message) error {
+	p.logger.WithField("event_type", message.Metadata[MetadataEventType]).
+		WithField("content", string(message.Content)).Info("event is published")
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/basket/events"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventsTestSuite struct {
+	suite.Suite
+}
+
+func TestEventsTestSuite(t *testing.T) {
+	suite.Run(t, new(EventsTestSuite))
+}
+
+func (s *EventsTestSuite) TestGivenCheckedOutEventThenItShouldBeDecodedBack() {
+	checkedOutAt := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	given := events.CheckedOut{
+		BasketID:     "b1",
+		UserID:       "u1",
+		Items:        []events.Item{{ProductID: "p1", Quantity: 2}},
+		CheckedOutAt: checkedOutAt,
+	}
+
+	message, err := events.NewMessage(given)
+	s.Require().Nil(err)
+
+	event, err := events.Decode(events.Type(message.Metadata[events.MetadataEventType]), message.Content)
+
+	s.Nil(err)
+	s.Equal(&given, event)
+}
+
+func (s *EventsTestSuite) TestGivenUnknownEventTypeThenItShouldNotBeDecoded() {
+	_, err := events.Decode("basket.unknown", []byte(`{}`))
+
+	s.NotNil(err)
+}
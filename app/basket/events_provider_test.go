@@ -0,0 +1,125 @@
+//go:build provider
+
+package basket_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/app/basket/events"
+	"github.com/pact-cdc-example/basket-service/app/internal/persistencetest"
+	"github.com/pact-cdc-example/basket-service/app/product"
+	"github.com/pact-foundation/pact-go/dsl"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+const localMessagePactPattern = "./events/pacts/*.json"
+
+// EventsProviderTestSuite verifies the messages the outbox relay publishes
+// when the service is used, not events built by hand for the test.
+type EventsProviderTestSuite struct {
+	suite.Suite
+	repo      persistencetest.MemoryRepository
+	publisher *recordingPublisher
+	service   basket.Service
+	relay     basket.OutboxRelay
+}
+
+func TestEventsProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(EventsProviderTestSuite))
+}
+
+func (s *EventsProviderTestSuite) SetupTest() {
+	logger := logrus.New()
+
+	productClient := &stubProductClient{products: map[string]product.Product{
+		givenProductID: givenCatalogProduct(),
+	}}
+
+	s.repo = persistencetest.NewMemoryRepository()
+	s.publisher = &recordingPublisher{}
+
+	s.service = basket.NewService(&basket.NewServiceOpts{
+		R: s.repo, L: logger, PC: productClient, SC: &stubStockClient{available: true},
+	})
+
+	s.relay = basket.NewOutboxRelay(&basket.NewOutboxRelayOpts{
+		R: s.repo, L: logger, SC: &stubStockClient{available: true}, EP: s.publisher,
+	})
+}
+
+func (s *EventsProviderTestSuite) TestGivenMessagePactsThenPublishedEventsShouldHonourThem() {
+	pact := &dsl.Pact{
+		Provider:                 providerBasketService,
+		DisableToolValidityCheck: true,
+		LogDir:                   "./pacts/logs",
+	}
+
+	pactURLs, err := filepath.Glob(localMessagePactPattern)
+	s.Require().Nil(err)
+	for i := range pactURLs {
+		pactURLs[i], err = filepath.Abs(pactURLs[i])
+		s.Require().Nil(err)
+	}
+
+	_, err = pact.VerifyMessageProvider(s.T(), dsl.VerifyMessageRequest{
+		PactURLs: pactURLs,
+		MessageHandlers: dsl.MessageHandlers{
+			"a basket created event": func(dsl.Message) (interface{}, error) {
+				return s.published(events.BasketCreatedType)
+			},
+			"an item added event": func(dsl.Message) (interface{}, error) {
+				return s.published(events.ItemAddedType)
+			},
+			"a basket checked out event": func(dsl.Message) (interface{}, error) {
+				return s.published(events.CheckedOutType)
+			},
+		},
+	})
+
+	s.Nil(err)
+}
+
+// published checks a basket out through the service, relays the outbox and
+// returns the content of the published message of eventType.
+func (s *EventsProviderTestSuite) published(eventType events.Type) (interface{}, error) {
+	ctx := context.Background()
+	s.repo.Reset()
+	s.publisher.messages = nil
+
+	created, err := s.service.CreateBasket(ctx, basket.CreateBasketRequest{UserID: givenUserID})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.service.AddProductToBasket(ctx, basket.AddProductToBasketRequest{
+		BasketID: created.ID, UserID: givenUserID, ProductID: givenProductID, Quantity: 2,
+	}); err != nil {
+		return nil, err
+	}
+
+	// the reservation has to be delivered before the basket is checked out
+	if err = s.relay.Relay(ctx); err != nil {
+		return nil, err
+	}
+
+	if _, err = s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: created.ID, UserID: givenUserID}); err != nil {
+		return nil, err
+	}
+
+	if err = s.relay.Relay(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, message := range s.publisher.messages {
+		if events.Type(message.Metadata[events.MetadataEventType]) == eventType {
+			return message.Content, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no %s event is published", eventType)
+}
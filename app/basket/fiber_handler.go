@@ -35,52 +35,60 @@ func NewHandler(opts *NewHandlerOpts) Handler {
 }
 
 func (h *handler) CreateBasket(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	var req CreateBasketRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(cerr.BodyParser())
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
 	}
 
 	basket, err := h.service.CreateBasket(ctx, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
 	}
 
-	return c.JSON(basket)
+	return h.respond(c, basket)
 }
 
 func (h *handler) AddProductToBasket(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	basketID := c.Params("basket_id")
 
 	var req AddProductToBasketRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(cerr.BodyParser())
+		return cerr.BodyParser()
 	}
 
 	req.BasketID = basketID
 
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
 	basket, err := h.service.AddProductToBasket(ctx, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
 	}
 
-	return c.JSON(basket)
+	return h.respond(c, basket)
 }
 
 func (h *handler) GetBasketByID(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	basketID := c.Params("basket_id")
 
 	basket, err := h.service.GetBasketByID(ctx, basketID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
 	}
 
-	return c.JSON(basket)
+	return h.respond(c, basket)
 }
 
 func (h *handler) AddBulkProductToBasket(c *fiber.Ctx) error {
@@ -88,18 +96,54 @@ func (h *handler) AddBulkProductToBasket(c *fiber.Ctx) error {
 
 	var req AddBulkProductToBasketRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(cerr.BodyParser())
+		return cerr.BodyParser()
 	}
 
 	req.BasketID = basketID
 
-	basket, err := h.service.AddBulkProductToBasket(c.Context(), req)
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	basket, err := h.service.AddBulkProductToBasket(c.UserContext(), req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
 	}
 
-	return c.JSON(basket)
+	return h.respond(c, basket)
+
+}
+
+func (h *handler) Checkout(c *fiber.Ctx) error {
+	req := CheckoutRequest{BasketID: c.Params("basket_id")}
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
 
+	req.BasketID = c.Params("basket_id")
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	basket, err := h.service.Checkout(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, basket)
+}
+
+// respond sends the basket with its warnings in the language of the request.
+func (h *handler) respond(c *fiber.Ctx, basket *GetBasketResponse) error {
+	c.Vary(fiber.HeaderAcceptLanguage)
+	if basket != nil && len(basket.Warnings) > 0 {
+		locale := cerr.NegotiateLocale(c.Get(fiber.HeaderAcceptLanguage))
+		basket.Localize(locale)
+		c.Set(fiber.HeaderContentLanguage, locale)
+	}
+
+	return c.JSON(basket)
 }
 
 func (h *handler) SetupRoutes(fr fiber.Router) {
@@ -109,4 +153,5 @@ func (h *handler) SetupRoutes(fr fiber.Router) {
 	basketGroup.Post("/:basket_id", h.AddProductToBasket)
 	basketGroup.Get("/:basket_id", h.GetBasketByID)
 	basketGroup.Post("/:basket_id/bulk", h.AddBulkProductToBasket)
+	basketGroup.Post("/:basket_id/checkout", h.Checkout)
 }
@@ -0,0 +1,37 @@
+package basket
+
+import (
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	basketsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "basket",
+		Name:      "baskets_created_total",
+		Help:      "Baskets created.",
+	})
+
+	itemsAdded = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "basket",
+		Name:      "items_added_total",
+		Help:      "Products added to baskets, a bulk request adds each of its products.",
+	})
+
+	outOfStockRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "basket",
+		Name:      "out_of_stock_rejections_total",
+		Help:      "Requests rejected because the stock could not satisfy them.",
+	})
+
+	checkouts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "basket",
+		Name:      "checkouts_total",
+		Help:      "Baskets checked out.",
+	})
+)
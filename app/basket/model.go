@@ -1,21 +1,51 @@
 package basket
 
-import "time"
+import (
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/product"
+)
 
 type Basket struct {
-	ID        string    `json:"-"`
-	UserID    string    `json:"-"`
-	Products  []Product `json:"-"`
-	CreatedAt time.Time `json:"-"`
-	UpdatedAt time.Time `json:"-"`
+	ID           string     `json:"-"`
+	UserID       string     `json:"-"`
+	Products     []Product  `json:"-"`
+	CheckedOutAt *time.Time `json:"-"`
+	CreatedAt    time.Time  `json:"-"`
+	UpdatedAt    time.Time  `json:"-"`
 }
 
 type Product struct {
-	ID        string    `json:"-"`
-	Quantity  int       `json:"-"`
-	BasketID  string    `json:"-"`
-	CreatedAt time.Time `json:"-"`
-	UpdatedAt time.Time `json:"-"`
+	ID        string           `json:"-"`
+	Quantity  int              `json:"-"`
+	BasketID  string           `json:"-"`
+	Snapshot  *ProductSnapshot `json:"-"`
+	CreatedAt time.Time        `json:"-"`
+	UpdatedAt time.Time        `json:"-"`
+}
+
+// ProductSnapshot is the product data as it was when the product was added to
+// the basket, it is served when the product api is not available.
+type ProductSnapshot struct {
+	Name     string  `json:"-"`
+	Code     string  `json:"-"`
+	Price    float64 `json:"-"`
+	ImageURL string  `json:"-"`
+	Type     string  `json:"-"`
+}
+
+func newProductSnapshot(prod *product.Product) *ProductSnapshot {
+	if prod == nil {
+		return nil
+	}
+
+	return &ProductSnapshot{
+		Name:     prod.Name,
+		Code:     prod.Code,
+		Price:    prod.Price,
+		ImageURL: prod.ImageURL,
+		Type:     prod.Type,
+	}
 }
 
 func getIDsOfProducts(products []Product) []string {
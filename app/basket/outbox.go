@@ -0,0 +1,321 @@
+package basket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/basket-service/app/basket/events"
+	"github.com/pact-cdc-example/basket-service/app/stock"
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
+	"github.com/sirupsen/logrus"
+)
+
+type CommandType string
+
+const (
+	CommandReserveStock CommandType = "reserve_stock"
+	CommandReleaseStock CommandType = "release_stock"
+	CommandCommitStock  CommandType = "commit_stock"
+	CommandPublishEvent CommandType = "publish_event"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusDelivered OutboxStatus = "delivered"
+	OutboxStatusDead      OutboxStatus = "dead"
+)
+
+// OutboxMessage is a command to the stock service, or an event to publish,
+// which is written in the same transaction as the basket change it belongs
+// to and delivered later by the outbox relay.
+type OutboxMessage struct {
+	ID        string          `json:"-"`
+	BasketID  string          `json:"-"`
+	Command   CommandType     `json:"-"`
+	Payload   json.RawMessage `json:"-"`
+	Status    OutboxStatus    `json:"-"`
+	Attempts  int             `json:"-"`
+	LastError string          `json:"-"`
+	CreatedAt time.Time       `json:"-"`
+}
+
+// StockCommand is the payload of the stock commands. StockID is empty until
+// the reservation is confirmed, it is only sent to release or commit it.
+type StockCommand struct {
+	ReservationID string `json:"reservation_id"`
+	StockID       string `json:"stock_id,omitempty"`
+	ProductID     string `json:"product_id"`
+	Quantity      int    `json:"quantity"`
+}
+
+func newStockCommandMessage(command CommandType, reservation *Reservation) (*OutboxMessage, error) {
+	payload, err := json.Marshal(StockCommand{
+		ReservationID: reservation.ID,
+		StockID:       reservation.StockID,
+		ProductID:     reservation.ProductID,
+		Quantity:      reservation.Quantity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxMessage{
+		ID:       uuid.New().String(),
+		BasketID: reservation.BasketID,
+		Command:  command,
+		Payload:  payload,
+		Status:   OutboxStatusPending,
+	}, nil
+}
+
+const (
+	defaultRelayBatchSize   = 50
+	defaultRelayMaxAttempts = 10
+	defaultRelayBaseBackoff = time.Second
+	defaultRelayMaxBackoff  = 5 * time.Minute
+	relayLease              = time.Minute
+)
+
+type OutboxRelay interface {
+	// Relay delivers one batch of due outbox messages.
+	Relay(ctx context.Context) error
+}
+
+type commandHandler func(ctx context.Context, payload json.RawMessage) error
+
+type outboxRelay struct {
+	repo        Repository
+	logger      *logrus.Logger
+	stockClient stock.Client
+	publisher   events.Publisher
+	batchSize   int
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	handlers    map[CommandType]commandHandler
+}
+
+type NewOutboxRelayOpts struct {
+	R           Repository
+	L           *logrus.Logger
+	SC          stock.Client
+	EP          events.Publisher
+	BatchSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+func NewOutboxRelay(opts *NewOutboxRelayOpts) OutboxRelay {
+	r := &outboxRelay{
+		repo:        opts.R,
+		logger:      opts.L,
+		stockClient: opts.SC,
+		publisher:   opts.EP,
+		batchSize:   opts.BatchSize,
+		maxAttempts: opts.MaxAttempts,
+		baseBackoff: opts.BaseBackoff,
+		maxBackoff:  opts.MaxBackoff,
+	}
+
+	if r.batchSize <= 0 {
+		r.batchSize = defaultRelayBatchSize
+	}
+	if r.maxAttempts <= 0 {
+		r.maxAttempts = defaultRelayMaxAttempts
+	}
+	if r.baseBackoff <= 0 {
+		r.baseBackoff = defaultRelayBaseBackoff
+	}
+	if r.maxBackoff <= 0 {
+		r.maxBackoff = defaultRelayMaxBackoff
+	}
+	if r.publisher == nil {
+		r.publisher = events.NewLogPublisher(&events.NewLogPublisherOpts{L: r.logger})
+	}
+
+	r.handlers = map[CommandType]commandHandler{
+		CommandReserveStock: stockCommandHandler(r.reserveStock),
+		CommandReleaseStock: stockCommandHandler(r.releaseStock),
+		CommandCommitStock:  stockCommandHandler(r.commitStock),
+		CommandPublishEvent: r.publishEvent,
+	}
+
+	return r
+}
+
+func (r *outboxRelay) Relay(ctx context.Context) error {
+	messages, err := r.repo.ClaimOutboxMessages(ctx, r.batchSize, relayLease)
+	if err != nil {
+		return err
+	}
+
+	for i := range messages {
+		r.deliver(ctx, &messages[i])
+	}
+
+	return nil
+}
+
+func (r *outboxRelay) deliver(ctx context.Context, message *OutboxMessage) {
+	logger := r.logger.WithField("outbox_message_id", message.ID).WithField("command", message.Command).
+		WithField("basket_id", message.BasketID)
+
+	err := r.handle(ctx, message)
+	if err == nil {
+		if err = r.repo.MarkOutboxMessageDelivered(ctx, message.ID); err != nil {
+			logger.Errorf("could not mark outbox message delivered: %v", err)
+		}
+		return
+	}
+
+	message.Attempts++
+	message.LastError = err.Error()
+
+	if !retryable(err) || message.Attempts >= r.maxAttempts {
+		message.Status = OutboxStatusDead
+		logger.Errorf("outbox message is dead lettered after %d attempts: %v", message.Attempts, err)
+		r.onDeadLetter(ctx, message)
+	} else {
+		logger.Warnf("could not deliver outbox message, it will be retried: %v", err)
+	}
+
+	if err = r.repo.MarkOutboxMessageFailed(ctx, message, r.backoff(message.Attempts)); err != nil {
+		logger.Errorf("could not mark outbox message failed: %v", err)
+	}
+}
+
+// retryable reports whether delivering again could succeed. An answer of the
+// stock service is retried only when the service failed, its error codes are
+// not the ones of this service and are not classified here.
+func retryable(err error) bool {
+	var respErr *httpclient.ResponseError
+	if errors.As(err, &respErr) {
+		return httpclient.IsServerError(err)
+	}
+
+	var bag cerr.Bag
+	if errors.As(err, &bag) {
+		return cerr.ClassOf(err).Retryable()
+	}
+
+	return true
+}
+
+func (r *outboxRelay) handle(ctx context.Context, message *OutboxMessage) error {
+	handler, ok := r.handlers[message.Command]
+	if !ok {
+		return fmt.Errorf("no handler for outbox command %q", message.Command)
+	}
+
+	return handler(ctx, message.Payload)
+}
+
+func stockCommandHandler(handle func(ctx context.Context, cmd StockCommand) error) commandHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var cmd StockCommand
+		if err := json.Unmarshal(payload, &cmd); err != nil {
+			return err
+		}
+
+		return handle(ctx, cmd)
+	}
+}
+
+func (r *outboxRelay) reserveStock(ctx context.Context, cmd StockCommand) error {
+	reservation, err := r.repo.GetReservationByID(ctx, cmd.ReservationID)
+	if err != nil {
+		return err
+	}
+
+	// the line is already gone, reserving now would leak the stock
+	if reservation.Status != ReservationStatusPending {
+		return nil
+	}
+
+	reservedStock, err := r.stockClient.ReserveStock(ctx, stock.ReserveStockRequest{
+		ReservationID: cmd.ReservationID,
+		ProductID:     cmd.ProductID,
+		Quantity:      cmd.Quantity,
+	})
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := r.repo.ConfirmReservation(ctx, cmd.ReservationID, reservedStock.ID)
+	if err != nil || confirmed {
+		return err
+	}
+
+	// the reservation is released or expired while the stock was reserved,
+	// the stock is released again instead of leaking.
+	reservation.StockID = reservedStock.ID
+	releaseStock, err := newStockCommandMessage(CommandReleaseStock, reservation)
+	if err != nil {
+		return err
+	}
+
+	return r.repo.CreateOutboxMessage(ctx, releaseStock)
+}
+
+func (r *outboxRelay) releaseStock(ctx context.Context, cmd StockCommand) error {
+	_, err := r.stockClient.ReleaseStock(ctx, stock.ReleaseStockRequest{
+		StockID:   cmd.StockID,
+		ProductID: cmd.ProductID,
+		Quantity:  cmd.Quantity,
+	})
+
+	return err
+}
+
+func (r *outboxRelay) commitStock(ctx context.Context, cmd StockCommand) error {
+	_, err := r.stockClient.CommitReservation(ctx, stock.CommitReservationRequest{
+		StockID:   cmd.StockID,
+		ProductID: cmd.ProductID,
+		Quantity:  cmd.Quantity,
+	})
+
+	return err
+}
+
+func (r *outboxRelay) publishEvent(ctx context.Context, payload json.RawMessage) error {
+	var message events.Message
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return err
+	}
+
+	return r.publisher.Publish(ctx, &message)
+}
+
+func (r *outboxRelay) onDeadLetter(ctx context.Context, message *OutboxMessage) {
+	if message.Command != CommandReserveStock {
+		return
+	}
+
+	var cmd StockCommand
+	if err := json.Unmarshal(message.Payload, &cmd); err != nil {
+		return
+	}
+
+	if _, err := r.repo.UpdateReservationStatus(
+		ctx, cmd.ReservationID, ReservationStatusPending, ReservationStatusFailed); err != nil {
+		r.logger.WithField("reservation_id", cmd.ReservationID).Errorf("could not mark reservation failed: %v", err)
+	}
+}
+
+func (r *outboxRelay) backoff(attempts int) time.Duration {
+	backoff := float64(r.baseBackoff) * math.Pow(2, float64(attempts-1))
+	if backoff > float64(r.maxBackoff) {
+		return r.maxBackoff
+	}
+
+	return time.Duration(backoff)
+}
@@ -0,0 +1,175 @@
+package basket_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/app/internal/persistencetest"
+	"github.com/pact-cdc-example/basket-service/app/stock"
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingRepository records the backoffs the relay asks for and makes the
+// failed messages due again right away, so a test can relay them again.
+type recordingRepository struct {
+	persistencetest.MemoryRepository
+	backoffs []time.Duration
+}
+
+func (r *recordingRepository) MarkOutboxMessageFailed(
+	ctx context.Context, message *basket.OutboxMessage, retryIn time.Duration) error {
+	r.backoffs = append(r.backoffs, retryIn)
+	return r.MemoryRepository.MarkOutboxMessageFailed(ctx, message, 0)
+}
+
+type OutboxRelayTestSuite struct {
+	suite.Suite
+	repo        *recordingRepository
+	stockClient *fakeStockClient
+	relay       basket.OutboxRelay
+}
+
+func TestOutboxRelayTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxRelayTestSuite))
+}
+
+func (s *OutboxRelayTestSuite) SetupTest() {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	s.repo = &recordingRepository{MemoryRepository: persistencetest.NewMemoryRepository()}
+	s.stockClient = &fakeStockClient{}
+	s.relay = basket.NewOutboxRelay(&basket.NewOutboxRelayOpts{
+		R:           s.repo,
+		L:           logger,
+		SC:          s.stockClient,
+		MaxAttempts: 4,
+		BaseBackoff: time.Second,
+		MaxBackoff:  3 * time.Second,
+	})
+}
+
+func (s *OutboxRelayTestSuite) TestGivenReserveCommandThenStockShouldBeReservedOnceWithReservationID() {
+	s.givenReserveCommand("r1", "p1", 2)
+
+	s.Require().Nil(s.relay.Relay(context.Background()))
+	s.Require().Nil(s.relay.Relay(context.Background()))
+
+	s.Equal([]stock.ReserveStockRequest{{ReservationID: "r1", ProductID: "p1", Quantity: 2}}, s.stockClient.reserved)
+
+	reservation := s.reservation("r1")
+	s.Equal(basket.ReservationStatusReserved, reservation.Status)
+	s.Equal("s-p1", reservation.StockID)
+}
+
+func (s *OutboxRelayTestSuite) TestGivenReservationReleasedBeforeDeliveryThenStockShouldNotBeReserved() {
+	s.givenReserveCommand("r1", "p1", 2)
+	s.releaseReservation("r1")
+
+	s.Require().Nil(s.relay.Relay(context.Background()))
+
+	s.Empty(s.stockClient.reserved)
+	s.Empty(s.stockClient.released)
+}
+
+func (s *OutboxRelayTestSuite) TestGivenReservationReleasedWhileReservingThenReservedStockShouldBeReleased() {
+	s.givenReserveCommand("r1", "p1", 2)
+	s.stockClient.onReserve = func() { s.releaseReservation("r1") }
+
+	s.Require().Nil(s.relay.Relay(context.Background()))
+	s.Require().Nil(s.relay.Relay(context.Background()))
+
+	s.Equal(basket.ReservationStatusReleased, s.reservation("r1").Status)
+	s.Equal([]stock.ReleaseStockRequest{{StockID: "s-p1", ProductID: "p1", Quantity: 2}}, s.stockClient.released)
+}
+
+func (s *OutboxRelayTestSuite) TestGivenFailingDeliveryThenItShouldBeRetriedWithBackoffUntilDeadLettered() {
+	s.givenReserveCommand("r1", "p1", 2)
+	s.stockClient.reserveErr = errors.New("connection refused")
+
+	for i := 0; i < 5; i++ {
+		s.Require().Nil(s.relay.Relay(context.Background()))
+	}
+
+	s.Len(s.stockClient.reserved, 4)
+	s.Equal([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}, s.repo.backoffs)
+	s.Equal(basket.ReservationStatusFailed, s.reservation("r1").Status)
+}
+
+func (s *OutboxRelayTestSuite) TestGivenRejectedCommandThenItShouldBeDeadLetteredWithoutRetrying() {
+	s.givenReserveCommand("r1", "p1", 2)
+	s.stockClient.reserveErr = &httpclient.ResponseError{
+		StatusCode: http.StatusBadRequest,
+		Bag:        cerr.Bag{Code: 30003, Message: "Not enough stock to reserve for given product."},
+	}
+
+	s.Require().Nil(s.relay.Relay(context.Background()))
+	s.Require().Nil(s.relay.Relay(context.Background()))
+
+	s.Len(s.stockClient.reserved, 1)
+	s.Equal(basket.ReservationStatusFailed, s.reservation("r1").Status)
+}
+
+func (s *OutboxRelayTestSuite) TestGivenUnavailableStockServiceThenCommandShouldBeRetried() {
+	s.givenReserveCommand("r1", "p1", 2)
+	// the code is one of the stock service, it is not classified by this one
+	s.stockClient.reserveErr = &httpclient.ResponseError{
+		StatusCode: http.StatusServiceUnavailable,
+		Bag:        cerr.Bag{Code: 10002, Message: "Service is unavailable."},
+	}
+
+	s.Require().Nil(s.relay.Relay(context.Background()))
+	s.Require().Nil(s.relay.Relay(context.Background()))
+
+	s.Len(s.stockClient.reserved, 2)
+	s.Equal([]time.Duration{time.Second, 2 * time.Second}, s.repo.backoffs)
+	s.Equal(basket.ReservationStatusPending, s.reservation("r1").Status)
+}
+
+func (s *OutboxRelayTestSuite) givenReserveCommand(reservationID string, productID string, quantity int) {
+	ctx := context.Background()
+
+	s.Require().Nil(s.repo.CreateReservation(ctx, &basket.Reservation{
+		ID:        reservationID,
+		BasketID:  "b1",
+		ProductID: productID,
+		Quantity:  quantity,
+		Status:    basket.ReservationStatusPending,
+	}))
+
+	payload, err := json.Marshal(basket.StockCommand{
+		ReservationID: reservationID,
+		ProductID:     productID,
+		Quantity:      quantity,
+	})
+	s.Require().Nil(err)
+
+	s.Require().Nil(s.repo.CreateOutboxMessage(ctx, &basket.OutboxMessage{
+		ID:       "m-" + reservationID,
+		BasketID: "b1",
+		Command:  basket.CommandReserveStock,
+		Payload:  payload,
+	}))
+}
+
+func (s *OutboxRelayTestSuite) reservation(id string) *basket.Reservation {
+	reservation, err := s.repo.GetReservationByID(context.Background(), id)
+	s.Require().Nil(err)
+
+	return reservation
+}
+
+func (s *OutboxRelayTestSuite) releaseReservation(id string) {
+	released, err := s.repo.UpdateReservationStatus(
+		context.Background(), id, basket.ReservationStatusPending, basket.ReservationStatusReleased)
+	s.Require().Nil(err)
+	s.Require().True(released)
+}
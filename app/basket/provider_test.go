@@ -0,0 +1,336 @@
+//go:build provider
+
+package basket_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/app/internal/persistencetest"
+	"github.com/pact-cdc-example/basket-service/app/product"
+	"github.com/pact-cdc-example/basket-service/app/stock"
+	"github.com/pact-cdc-example/basket-service/pkg/server"
+	"github.com/pact-foundation/pact-go/dsl"
+	"github.com/pact-foundation/pact-go/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+const providerBasketService = "BasketService"
+
+// the ids the consumers refer to in their provider states.
+const (
+	givenBasketID  = "1f0a8e3c-6b5d-4c2e-9a7f-3d8b1e4c6a20"
+	givenUserID    = "6d2b9f4e-1a3c-4e8b-b7d5-0c9e2f1a3b84"
+	givenProductID = "9c4e1b7a-3f2d-4a6e-8b5c-7d0f2e9a1c36"
+)
+
+type stubProductClient struct {
+	products map[string]product.Product
+}
+
+func (c *stubProductClient) GetProductByID(_ context.Context, id string) (*product.Product, error) {
+	prod, ok := c.products[id]
+	if !ok {
+		return nil, product.ProductNotFound()
+	}
+
+	return &prod, nil
+}
+
+func (c *stubProductClient) GetProductsByIDs(
+	_ context.Context, req product.GetProductByIDsRequest) ([]product.Product, error) {
+	products := make([]product.Product, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		prod, ok := c.products[id]
+		if !ok {
+			return nil, product.SomeProductsNotFound()
+		}
+		products = append(products, prod)
+	}
+
+	return products, nil
+}
+
+type stubStockClient struct {
+	available bool
+}
+
+func (c *stubStockClient) IsProductAvailableInStock(
+	_ context.Context, _ stock.IsProductAvailableInStockRequest) (bool, error) {
+	return c.available, nil
+}
+
+func (c *stubStockClient) ReserveStock(
+	_ context.Context, req stock.ReserveStockRequest) (*stock.Stock, error) {
+	return &stock.Stock{ProductID: req.ProductID, ReservedQuantity: req.Quantity}, nil
+}
+
+func (c *stubStockClient) GetStockByProductID(
+	_ context.Context, productID string) (*stock.Stock, error) {
+	return &stock.Stock{ProductID: productID}, nil
+}
+
+func (c *stubStockClient) ReleaseStock(
+	_ context.Context, req stock.ReleaseStockRequest) (*stock.Stock, error) {
+	return &stock.Stock{ProductID: req.ProductID}, nil
+}
+
+func (c *stubStockClient) CommitReservation(
+	_ context.Context, req stock.CommitReservationRequest) (*stock.Stock, error) {
+	return &stock.Stock{ProductID: req.ProductID}, nil
+}
+
+func (c *stubStockClient) CheckAvailability(
+	_ context.Context, req stock.CheckAvailabilityRequest) ([]stock.ProductAvailability, error) {
+	availabilities := make([]stock.ProductAvailability, len(req.Products))
+	for i, p := range req.Products {
+		availabilities[i] = stock.ProductAvailability{ProductID: p.ProductID, IsAvailable: c.available}
+	}
+
+	return availabilities, nil
+}
+
+type BasketProviderTestSuite struct {
+	suite.Suite
+	repo          persistencetest.MemoryRepository
+	productClient *stubProductClient
+	stockClient   *stubStockClient
+	serverURL     string
+}
+
+func TestBasketProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(BasketProviderTestSuite))
+}
+
+func (s *BasketProviderTestSuite) SetupSuite() {
+	logger := logrus.New()
+
+	s.repo = persistencetest.NewMemoryRepository()
+	s.productClient = &stubProductClient{}
+	s.stockClient = &stubStockClient{}
+
+	basketService := basket.NewService(&basket.NewServiceOpts{
+		R: s.repo, L: logger, PC: s.productClient, SC: s.stockClient,
+	})
+
+	basketHandler := basket.NewHandler(&basket.NewHandlerOpts{
+		S: basketService, L: logger,
+	})
+
+	port, err := freePort()
+	s.Require().Nil(err)
+
+	app := server.New(&server.NewServerOpts{
+		Port: port,
+		L:    logger,
+	}, []server.RouteHandler{
+		basketHandler,
+	})
+
+	go func() {
+		_ = app.Run()
+	}()
+
+	s.serverURL = fmt.Sprintf("http://127.0.0.1:%s", port)
+	s.Require().Nil(waitUntilServing(s.serverURL+"/liveness", 5*time.Second))
+}
+
+func (s *BasketProviderTestSuite) TestGivenConsumerPactsThenBasketAPIShouldHonourThem() {
+	pact := &dsl.Pact{
+		Provider:                 providerBasketService,
+		DisableToolValidityCheck: true,
+		LogDir:                   "./pacts/logs",
+	}
+
+	req := types.VerifyRequest{
+		ProviderBaseURL: s.serverURL,
+		Provider:        providerBasketService,
+		ProviderVersion: os.Getenv("PROVIDER_VERSION"),
+		ProviderBranch:  os.Getenv("BRANCH_NAME"),
+		BeforeEach:      s.reset,
+		StateHandlers:   s.stateHandlers(),
+	}
+
+	if brokerURL := os.Getenv("BROKER_URL"); brokerURL != "" {
+		req.BrokerURL = brokerURL
+		req.BrokerToken = os.Getenv("BROKER_TOKEN")
+		req.BrokerUsername = os.Getenv("BROKER_USERNAME")
+		req.BrokerPassword = os.Getenv("BROKER_PASSWORD")
+		req.ConsumerVersionSelectors = []types.ConsumerVersionSelector{
+			{MainBranch: true},
+			{DeployedOrReleased: true},
+		}
+		req.PublishVerificationResults = req.ProviderVersion != ""
+		req.FailIfNoPactsFound = true
+	} else {
+		pactURLs, err := localPactURLs()
+		s.Require().Nil(err)
+		if len(pactURLs) == 0 {
+			s.T().Skip("no consumer pacts to verify, set BROKER_URL or PACT_DIR")
+		}
+		req.PactURLs = pactURLs
+	}
+
+	_, err := pact.VerifyProvider(s.T(), req)
+
+	s.Nil(err)
+}
+
+func (s *BasketProviderTestSuite) reset() error {
+	s.repo.Reset()
+	s.productClient.products = map[string]product.Product{}
+	s.stockClient.available = true
+
+	return nil
+}
+
+func (s *BasketProviderTestSuite) stateHandlers() types.StateHandlers {
+	return types.StateHandlers{
+		fmt.Sprintf("basket with id %s exists", givenBasketID): func() error {
+			s.givenProductInCatalog()
+			return s.givenBasketWithProduct(basket.ReservationStatusReserved)
+		},
+		fmt.Sprintf("basket with id %s does not exist", givenBasketID): func() error {
+			return nil
+		},
+		fmt.Sprintf("basket with id %s is checked out", givenBasketID): func() error {
+			s.givenProductInCatalog()
+			if err := s.givenBasketWithProduct(basket.ReservationStatusReserved); err != nil {
+				return err
+			}
+			_, err := s.repo.CheckoutBasket(context.Background(), givenBasketID)
+			return err
+		},
+		fmt.Sprintf("basket with id %s has pending reservations", givenBasketID): func() error {
+			s.givenProductInCatalog()
+			return s.givenBasketWithProduct(basket.ReservationStatusPending)
+		},
+		"product in stock": func() error {
+			s.givenProductInCatalog()
+			s.stockClient.available = true
+			return s.givenEmptyBasket()
+		},
+		"product out of stock": func() error {
+			s.givenProductInCatalog()
+			s.stockClient.available = false
+			return s.givenEmptyBasket()
+		},
+	}
+}
+
+func (s *BasketProviderTestSuite) givenProductInCatalog() {
+	s.productClient.products[givenProductID] = givenCatalogProduct()
+}
+
+func givenCatalogProduct() product.Product {
+	return product.Product{
+		ID:        givenProductID,
+		Name:      "Granny Smith",
+		Code:      "apple-green",
+		Color:     "green",
+		CreatedAt: time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2022, time.March, 2, 10, 0, 0, 0, time.UTC),
+		Price:     12.5,
+		ImageURL:  "https://picsum.photos/200/100",
+		Type:      "fruit",
+	}
+}
+
+func (s *BasketProviderTestSuite) givenEmptyBasket() error {
+	_, err := s.repo.CreateBasket(context.Background(), &basket.Basket{
+		ID:     givenBasketID,
+		UserID: givenUserID,
+	})
+
+	return err
+}
+
+// givenBasketWithProduct creates the given basket with one line of the given
+// product whose reservation is in status.
+func (s *BasketProviderTestSuite) givenBasketWithProduct(status basket.ReservationStatus) error {
+	ctx := context.Background()
+	prod := s.productClient.products[givenProductID]
+
+	if err := s.givenEmptyBasket(); err != nil {
+		return err
+	}
+
+	_, err := s.repo.AddProductToBasket(ctx, &basket.Product{
+		ID:       givenProductID,
+		Quantity: 2,
+		BasketID: givenBasketID,
+		Snapshot: &basket.ProductSnapshot{
+			Name:     prod.Name,
+			Code:     prod.Code,
+			Price:    prod.Price,
+			ImageURL: prod.ImageURL,
+			Type:     prod.Type,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.repo.CreateReservation(ctx, &basket.Reservation{
+		ID:        "4b8e2d6f-0a3c-4f9e-a1d7-5c2b8e0f3a69",
+		BasketID:  givenBasketID,
+		ProductID: givenProductID,
+		Quantity:  2,
+		Status:    status,
+	})
+}
+
+// localPactURLs returns the pacts of the consumers of this service which are
+// kept in PACT_DIR, such as the pacts the frontend tests write. The basket
+// service does not keep pacts of its consumers, they have to come from them.
+func localPactURLs() ([]string, error) {
+	dir := os.Getenv("PACT_DIR")
+	if dir == "" {
+		return nil, nil
+	}
+
+	pactURLs, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, pactURL := range pactURLs {
+		if pactURLs[i], err = filepath.Abs(pactURL); err != nil {
+			return nil, err
+		}
+	}
+
+	return pactURLs, nil
+}
+
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+
+	return fmt.Sprint(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+func waitUntilServing(url string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		res, err := http.Get(url)
+		if err == nil {
+			res.Body.Close()
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return fmt.Errorf("server is not serving at %s", url)
+}
@@ -0,0 +1,85 @@
+package basket
+
+import (
+	"context"
+
+	"github.com/pact-cdc-example/basket-service/app/stock"
+	"github.com/sirupsen/logrus"
+)
+
+type ReservationReconciler interface {
+	// Reconcile compares the active reservations of the ledger with the
+	// reserved quantities known by the stock service.
+	Reconcile(ctx context.Context) (*ReconciliationReport, error)
+}
+
+type ReconciliationReport struct {
+	CheckedProducts int
+	Mismatches      []ReservationMismatch
+}
+
+// ReservationMismatch is reported when the stock service holds less stock
+// reserved for a product than the ledger has active reservations of.
+type ReservationMismatch struct {
+	ProductID             string
+	LedgerQuantity        int
+	StockReservedQuantity int
+}
+
+type reservationReconciler struct {
+	repo        Repository
+	logger      *logrus.Logger
+	stockClient stock.Client
+}
+
+type NewReservationReconcilerOpts struct {
+	R  Repository
+	L  *logrus.Logger
+	SC stock.Client
+}
+
+func NewReservationReconciler(opts *NewReservationReconcilerOpts) ReservationReconciler {
+	return &reservationReconciler{
+		repo:        opts.R,
+		logger:      opts.L,
+		stockClient: opts.SC,
+	}
+}
+
+func (rr *reservationReconciler) Reconcile(ctx context.Context) (*ReconciliationReport, error) {
+	reservations, err := rr.repo.GetReservationsByStatus(ctx, ReservationStatusReserved, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ledgerQuantities := make(map[string]int)
+	for _, reservation := range reservations {
+		ledgerQuantities[reservation.ProductID] += reservation.Quantity
+	}
+
+	report := &ReconciliationReport{}
+	for productID, ledgerQuantity := range ledgerQuantities {
+		productStock, err := rr.stockClient.GetStockByProductID(ctx, productID)
+		if err != nil {
+			rr.logger.WithField("product_id", productID).Errorf("could not get stock to reconcile: %v", err)
+			continue
+		}
+
+		report.CheckedProducts++
+
+		if productStock.ReservedQuantity < ledgerQuantity {
+			mismatch := ReservationMismatch{
+				ProductID:             productID,
+				LedgerQuantity:        ledgerQuantity,
+				StockReservedQuantity: productStock.ReservedQuantity,
+			}
+			report.Mismatches = append(report.Mismatches, mismatch)
+
+			rr.logger.WithField("product_id", productID).WithField("ledger_quantity", ledgerQuantity).
+				WithField("stock_reserved_quantity", productStock.ReservedQuantity).
+				Warn("reservation ledger does not match stock service")
+		}
+	}
+
+	return report, nil
+}
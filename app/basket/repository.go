@@ -1,9 +1,45 @@
 package basket
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Repository interface {
 	CreateBasket(ctx context.Context, basket *Basket) (*Basket, error)
 	GetBasketByID(ctx context.Context, basketID string) (*Basket, error)
 	AddProductToBasket(ctx context.Context, product *Product) (*Basket, error)
+	RemoveProductFromBasket(ctx context.Context, basketID string, productID string) (*Basket, error)
+	// CheckoutBasket fails with BasketCheckedOut when the basket is already
+	// checked out.
+	CheckoutBasket(ctx context.Context, basketID string) (*Basket, error)
+
+	CreateReservation(ctx context.Context, reservation *Reservation) error
+	// UpdateReservationStatus moves a reservation from status from to status
+	// to. It reports false when the reservation is not in status from
+	// anymore, such as when it is committed or expired meanwhile.
+	UpdateReservationStatus(
+		ctx context.Context, reservationID string, from ReservationStatus, to ReservationStatus) (bool, error)
+	GetReservationsByBasketID(ctx context.Context, basketID string) ([]Reservation, error)
+	// LockReservationsByBasketID returns the reservations of the basket and
+	// keeps them from being changed by others until the transaction of the
+	// repository ends.
+	LockReservationsByBasketID(ctx context.Context, basketID string) ([]Reservation, error)
+	// GetReservationsByStatus returns the reservations in status which are
+	// not updated for at least unchangedFor.
+	GetReservationsByStatus(
+		ctx context.Context, status ReservationStatus, unchangedFor time.Duration) ([]Reservation, error)
+	GetReservationByID(ctx context.Context, reservationID string) (*Reservation, error)
+	// ConfirmReservation marks a pending reservation reserved with the id the
+	// stock service answered it with. It reports false when the reservation
+	// is not pending anymore, such as when its line was removed meanwhile.
+	ConfirmReservation(ctx context.Context, reservationID string, stockID string) (bool, error)
+
+	CreateOutboxMessage(ctx context.Context, message *OutboxMessage) error
+	ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]OutboxMessage, error)
+	MarkOutboxMessageDelivered(ctx context.Context, messageID string) error
+	MarkOutboxMessageFailed(ctx context.Context, message *OutboxMessage, retryIn time.Duration) error
+
+	// RunInTx runs fn with a repository whose writes are committed together.
+	RunInTx(ctx context.Context, fn func(repo Repository) error) error
 }
@@ -1,17 +1,20 @@
 package basket
 
-import "errors"
+import (
+	"fmt"
+
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+)
 
 type CreateBasketRequest struct {
 	UserID string `json:"user_id"`
 }
 
 func (cbr CreateBasketRequest) Validate() error {
-	if cbr.UserID == "" {
-		return errors.New("user id can not be empty")
-	}
+	var v cerr.Validator
+	v.Required("user_id", cbr.UserID)
 
-	return nil
+	return v.Err()
 }
 
 type AddProductToBasketRequest struct {
@@ -21,11 +24,52 @@ type AddProductToBasketRequest struct {
 	Quantity  int    `json:"quantity"`
 }
 
-type AddBulkProductToBasketRequest struct {
-	UserID   string `json:"user_id"`
+func (apr AddProductToBasketRequest) Validate() error {
+	var v cerr.Validator
+	v.Required("basket_id", apr.BasketID)
+	v.Required("user_id", apr.UserID)
+	v.Required("product_id", apr.ProductID)
+	v.Min("quantity", apr.Quantity, 1)
+
+	return v.Err()
+}
+
+type CheckoutRequest struct {
 	BasketID string `json:"basket_id"`
-	Products []struct {
-		ID       string `json:"id"`
-		Quantity int    `json:"quantity"`
-	} `json:"products"`
+	UserID   string `json:"user_id"`
+}
+
+func (cr CheckoutRequest) Validate() error {
+	var v cerr.Validator
+	v.Required("basket_id", cr.BasketID)
+	v.Required("user_id", cr.UserID)
+
+	return v.Err()
+}
+
+type AddBulkProductToBasketRequest struct {
+	UserID   string        `json:"user_id"`
+	BasketID string        `json:"basket_id"`
+	Products []BulkProduct `json:"products"`
+}
+
+func (abr AddBulkProductToBasketRequest) Validate() error {
+	var v cerr.Validator
+	v.Required("user_id", abr.UserID)
+	v.Required("basket_id", abr.BasketID)
+	if len(abr.Products) == 0 {
+		v.Add("products", cerr.RuleRequired, "products must be given.")
+	}
+
+	for i, prod := range abr.Products {
+		v.Required(fmt.Sprintf("products[%d].id", i), prod.ID)
+		v.Min(fmt.Sprintf("products[%d].quantity", i), prod.Quantity, 1)
+	}
+
+	return v.Err()
+}
+
+type BulkProduct struct {
+	ID       string `json:"id"`
+	Quantity int    `json:"quantity"`
 }
@@ -0,0 +1,80 @@
+package basket_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/stretchr/testify/suite"
+)
+
+type BasketRequestTestSuite struct {
+	suite.Suite
+}
+
+func TestBasketRequestTestSuite(t *testing.T) {
+	suite.Run(t, new(BasketRequestTestSuite))
+}
+
+func (s *BasketRequestTestSuite) TestGivenValidRequestsThenTheyShouldPass() {
+	s.Nil(basket.CreateBasketRequest{UserID: "u1"}.Validate())
+	s.Nil(basket.AddProductToBasketRequest{BasketID: "b1", UserID: "u1", ProductID: "p1", Quantity: 1}.Validate())
+	s.Nil(basket.CheckoutRequest{BasketID: "b1", UserID: "u1"}.Validate())
+	s.Nil(basket.AddBulkProductToBasketRequest{
+		BasketID: "b1",
+		UserID:   "u1",
+		Products: []basket.BulkProduct{{ID: "p1", Quantity: 2}},
+	}.Validate())
+}
+
+func (s *BasketRequestTestSuite) TestGivenEmptyProductAndZeroQuantityThenBothFieldsShouldBeReported() {
+	err := basket.AddProductToBasketRequest{BasketID: "b1", UserID: "u1", ProductID: " ", Quantity: 0}.Validate()
+
+	bag := s.validationBag(err)
+	s.Require().Len(bag.Details, 2)
+	s.Equal("product_id", bag.Details[0].Field)
+	s.Equal(cerr.RuleRequired, bag.Details[0].Rule)
+	s.Equal("product_id must be given.", bag.Details[0].Message)
+	s.Equal("quantity", bag.Details[1].Field)
+	s.Equal(cerr.RuleMin, bag.Details[1].Rule)
+	s.Equal("quantity must be at least 1.", bag.Details[1].Message)
+}
+
+func (s *BasketRequestTestSuite) TestGivenNegativeQuantityInBulkThenTheProductShouldBeReported() {
+	err := basket.AddBulkProductToBasketRequest{
+		BasketID: "b1",
+		UserID:   "u1",
+		Products: []basket.BulkProduct{{ID: "p1", Quantity: 1}, {ID: "p2", Quantity: -3}},
+	}.Validate()
+
+	bag := s.validationBag(err)
+	s.Len(bag.Details, 1)
+	s.Equal("products[1].quantity", bag.Details[0].Field)
+	s.Equal(cerr.RuleMin, bag.Details[0].Rule)
+}
+
+func (s *BasketRequestTestSuite) TestGivenNoProductsInBulkThenItShouldBeReported() {
+	err := basket.AddBulkProductToBasketRequest{BasketID: "b1", UserID: "u1"}.Validate()
+
+	bag := s.validationBag(err)
+	s.Equal("products", bag.Details[0].Field)
+	s.Equal(cerr.RuleRequired, bag.Details[0].Rule)
+}
+
+func (s *BasketRequestTestSuite) TestGivenMissingUserThenCheckoutShouldBeReported() {
+	err := basket.CheckoutRequest{BasketID: "b1"}.Validate()
+
+	bag := s.validationBag(err)
+	s.Equal("user_id", bag.Details[0].Field)
+}
+
+func (s *BasketRequestTestSuite) validationBag(err error) cerr.Bag {
+	var bag cerr.Bag
+	s.Require().True(errors.As(err, &bag))
+	s.Equal(cerr.ValidationErrCode, bag.Code)
+	s.Equal(http.StatusUnprocessableEntity, bag.Status())
+
+	return bag
+}
@@ -0,0 +1,104 @@
+package basket
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationStatusPending   ReservationStatus = "pending"
+	ReservationStatusReserved  ReservationStatus = "reserved"
+	ReservationStatusReleased  ReservationStatus = "released"
+	ReservationStatusExpired   ReservationStatus = "expired"
+	ReservationStatusCommitted ReservationStatus = "committed"
+	ReservationStatusFailed    ReservationStatus = "failed"
+)
+
+// Reservation is a ledger entry of the stock reserved for a basket line. It is
+// pending until the outbox relay delivers it, StockID is the id the stock
+// service answered the reservation with.
+type Reservation struct {
+	ID        string            `json:"-"`
+	BasketID  string            `json:"-"`
+	ProductID string            `json:"-"`
+	Quantity  int               `json:"-"`
+	StockID   string            `json:"-"`
+	Status    ReservationStatus `json:"-"`
+	CreatedAt time.Time         `json:"-"`
+	UpdatedAt time.Time         `json:"-"`
+}
+
+// isActive reports whether the reservation holds or is about to hold stock.
+func (r *Reservation) isActive() bool {
+	return r.Status == ReservationStatusPending || r.Status == ReservationStatusReserved
+}
+
+type ReservationExpirer interface {
+	// ExpireReservations marks the reservations which are held longer than
+	// the reservation ttl as expired and releases their stock.
+	ExpireReservations(ctx context.Context) error
+}
+
+type reservationExpirer struct {
+	repo   Repository
+	logger *logrus.Logger
+	ttl    time.Duration
+}
+
+type NewReservationExpirerOpts struct {
+	R   Repository
+	L   *logrus.Logger
+	TTL time.Duration
+}
+
+func NewReservationExpirer(opts *NewReservationExpirerOpts) ReservationExpirer {
+	return &reservationExpirer{
+		repo:   opts.R,
+		logger: opts.L,
+		ttl:    opts.TTL,
+	}
+}
+
+func (re *reservationExpirer) ExpireReservations(ctx context.Context) error {
+	reservations, err := re.repo.GetReservationsByStatus(
+		ctx, ReservationStatusReserved, re.ttl)
+	if err != nil {
+		return err
+	}
+
+	for i := range reservations {
+		reservation := &reservations[i]
+
+		message, err := newStockCommandMessage(CommandReleaseStock, reservation)
+		if err != nil {
+			return err
+		}
+
+		// the reservation is read outside of the transaction, it expires only
+		// if it is still reserved so a checkout committing it meanwhile wins.
+		var expired bool
+		if err = re.repo.RunInTx(ctx, func(repo Repository) error {
+			var err error
+			if expired, err = repo.UpdateReservationStatus(
+				ctx, reservation.ID, ReservationStatusReserved, ReservationStatusExpired); err != nil || !expired {
+				return err
+			}
+			return repo.CreateOutboxMessage(ctx, message)
+		}); err != nil {
+			return err
+		}
+
+		if !expired {
+			continue
+		}
+
+		re.logger.WithField("reservation_id", reservation.ID).WithField("basket_id", reservation.BasketID).
+			Info("reservation expired")
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package basket_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/app/internal/persistencetest"
+	"github.com/pact-cdc-example/basket-service/app/stock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReservationTestSuite struct {
+	suite.Suite
+	repo        persistencetest.MemoryRepository
+	stockClient *fakeStockClient
+	logger      *logrus.Logger
+}
+
+func TestReservationTestSuite(t *testing.T) {
+	suite.Run(t, new(ReservationTestSuite))
+}
+
+func (s *ReservationTestSuite) SetupTest() {
+	s.logger = logrus.New()
+	s.logger.SetLevel(logrus.PanicLevel)
+
+	s.repo = persistencetest.NewMemoryRepository()
+	s.stockClient = &fakeStockClient{}
+}
+
+func (s *ReservationTestSuite) TestGivenReservationHeldLongerThanTTLThenItShouldBeExpiredAndReleasedByStockID() {
+	s.givenReservation("r1", "p1", 2, basket.ReservationStatusReserved, "s1")
+	s.givenReservation("r2", "p2", 1, basket.ReservationStatusPending, "")
+
+	err := s.newExpirer(0).ExpireReservations(context.Background())
+	s.Require().Nil(err)
+
+	s.Equal(basket.ReservationStatusExpired, s.reservationStatus("r1"))
+	s.Equal(basket.ReservationStatusPending, s.reservationStatus("r2"))
+
+	s.Require().Nil(s.newRelay().Relay(context.Background()))
+	s.Equal([]stock.ReleaseStockRequest{{StockID: "s1", ProductID: "p1", Quantity: 2}}, s.stockClient.released)
+}
+
+func (s *ReservationTestSuite) TestGivenReservationHeldShorterThanTTLThenItShouldBeKept() {
+	s.givenReservation("r1", "p1", 2, basket.ReservationStatusReserved, "s1")
+
+	err := s.newExpirer(time.Hour).ExpireReservations(context.Background())
+	s.Require().Nil(err)
+
+	s.Equal(basket.ReservationStatusReserved, s.reservationStatus("r1"))
+	s.Require().Nil(s.newRelay().Relay(context.Background()))
+	s.Empty(s.stockClient.released)
+}
+
+func (s *ReservationTestSuite) TestGivenLedgerHoldingMoreThanStockServiceThenReconcilerShouldReportMismatch() {
+	s.givenReservation("r1", "p1", 2, basket.ReservationStatusReserved, "s1")
+	s.givenReservation("r2", "p1", 3, basket.ReservationStatusReserved, "s1")
+	s.givenReservation("r3", "p2", 1, basket.ReservationStatusReserved, "s2")
+	s.givenReservation("r4", "p3", 1, basket.ReservationStatusReserved, "s3")
+	s.givenReservation("r5", "p2", 4, basket.ReservationStatusReleased, "s2")
+	s.stockClient.stocks = map[string]stock.Stock{
+		"p1": {ID: "s1", ProductID: "p1", ReservedQuantity: 4},
+		"p2": {ID: "s2", ProductID: "p2", ReservedQuantity: 1},
+	}
+
+	report, err := basket.NewReservationReconciler(&basket.NewReservationReconcilerOpts{
+		R: s.repo, L: s.logger, SC: s.stockClient,
+	}).Reconcile(context.Background())
+
+	s.Require().Nil(err)
+	// p3 has no stock to compare with, it is skipped
+	s.Equal(2, report.CheckedProducts)
+	s.Equal([]basket.ReservationMismatch{
+		{ProductID: "p1", LedgerQuantity: 5, StockReservedQuantity: 4},
+	}, report.Mismatches)
+}
+
+func (s *ReservationTestSuite) newExpirer(ttl time.Duration) basket.ReservationExpirer {
+	return basket.NewReservationExpirer(&basket.NewReservationExpirerOpts{R: s.repo, L: s.logger, TTL: ttl})
+}
+
+func (s *ReservationTestSuite) newRelay() basket.OutboxRelay {
+	return basket.NewOutboxRelay(&basket.NewOutboxRelayOpts{R: s.repo, L: s.logger, SC: s.stockClient})
+}
+
+func (s *ReservationTestSuite) givenReservation(
+	id string, productID string, quantity int, status basket.ReservationStatus, stockID string) {
+	s.Require().Nil(s.repo.CreateReservation(context.Background(), &basket.Reservation{
+		ID:        id,
+		BasketID:  "b1",
+		ProductID: productID,
+		Quantity:  quantity,
+		StockID:   stockID,
+		Status:    status,
+	}))
+}
+
+func (s *ReservationTestSuite) reservationStatus(id string) basket.ReservationStatus {
+	reservation, err := s.repo.GetReservationByID(context.Background(), id)
+	s.Require().Nil(err)
+
+	return reservation.Status
+}
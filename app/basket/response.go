@@ -1,44 +1,218 @@
 package basket
 
 import (
+	"math"
+
 	"github.com/pact-cdc-example/basket-service/app/product"
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+)
+
+const (
+	ProductStatusAvailable   = "available"
+	ProductStatusUnavailable = "unavailable"
+	ProductStatusUnknown     = "unknown"
+)
+
+const (
+	UnavailableReasonNotInCatalog = "not_in_catalog"
+	// UnavailableReasonNoSnapshot is given for the lines added before
+	// snapshots were stored while the product api is not available, nothing
+	// is known about their product.
+	UnavailableReasonNoSnapshot = "no_snapshot"
 )
 
 type GetBasketResponse struct {
-	ID        string                `json:"id"`
-	UserID    string                `json:"user_id"`
-	Products  []ProductQuantityPair `json:"products,omitempty"`
-	CreatedAt string                `json:"created_at"`
-	UpdatedAt string                `json:"updated_at"`
+	ID           string                `json:"id"`
+	UserID       string                `json:"user_id"`
+	Products     []ProductQuantityPair `json:"products,omitempty"`
+	CreatedAt    string                `json:"created_at"`
+	UpdatedAt    string                `json:"updated_at"`
+	CheckedOutAt string                `json:"checked_out_at,omitempty"`
+	Warnings     []cerr.Bag            `json:"warnings,omitempty"`
 }
 
+// NewBasketResponse joins the basket lines with the given products. Lines
+// whose product is not among them are reported as unavailable.
 func NewBasketResponse(basket *Basket, products []product.Product) *GetBasketResponse {
 	if basket == nil {
 		return nil
 	}
 
+	productsByID := make(map[string]*product.Product, len(products))
+	for i := range products {
+		productsByID[products[i].ID] = &products[i]
+	}
+
+	var hasPriceChange, hasUnavailable bool
 	productQuantityPairs := make([]ProductQuantityPair, len(basket.Products))
-	for i := range basket.Products {
-		for j := range products {
-			if basket.Products[i].ID == products[j].ID {
-				productQuantityPairs[i] = ProductQuantityPair{
-					Product:  &products[j],
-					Quantity: basket.Products[i].Quantity,
-				}
-			}
+	for i, p := range basket.Products {
+		prod, ok := productsByID[p.ID]
+		if !ok {
+			hasUnavailable = true
+			productQuantityPairs[i] = newUnavailablePair(p, UnavailableReasonNotInCatalog)
+			continue
+		}
+
+		productQuantityPairs[i] = ProductQuantityPair{
+			Product:     prod,
+			Quantity:    p.Quantity,
+			Status:      ProductStatusAvailable,
+			PriceChange: newPriceChange(p.Snapshot, prod),
 		}
+		hasPriceChange = hasPriceChange || productQuantityPairs[i].PriceChange != nil
+	}
+
+	var warnings []cerr.Bag
+	if hasPriceChange {
+		warnings = append(warnings, PriceChanged())
+	}
+	if hasUnavailable {
+		warnings = append(warnings, UnavailableProducts())
 	}
 
 	return &GetBasketResponse{
-		ID:        basket.ID,
-		UserID:    basket.UserID,
-		CreatedAt: basket.CreatedAt.Format(layoutISO),
-		UpdatedAt: basket.UpdatedAt.Format(layoutISO),
-		Products:  productQuantityPairs,
+		ID:           basket.ID,
+		UserID:       basket.UserID,
+		CreatedAt:    basket.CreatedAt.Format(layoutISO),
+		UpdatedAt:    basket.UpdatedAt.Format(layoutISO),
+		CheckedOutAt: formatCheckedOutAt(basket),
+		Products:     productQuantityPairs,
+		Warnings:     warnings,
+	}
+}
+
+// NewStaleBasketResponse builds the basket from the product snapshots saved
+// on the basket lines, it is used when the product api is not available.
+func NewStaleBasketResponse(basket *Basket) *GetBasketResponse {
+	if basket == nil {
+		return nil
+	}
+
+	warnings := []cerr.Bag{StaleProductData()}
+	hasUnavailable := false
+
+	productQuantityPairs := make([]ProductQuantityPair, len(basket.Products))
+	for i, p := range basket.Products {
+		if p.Snapshot == nil {
+			hasUnavailable = true
+			productQuantityPairs[i] = newUnavailablePair(p, UnavailableReasonNoSnapshot)
+			continue
+		}
+
+		productQuantityPairs[i] = ProductQuantityPair{
+			Product:  newProductFromSnapshot(p),
+			Quantity: p.Quantity,
+			Status:   ProductStatusUnknown,
+			Stale:    true,
+		}
+	}
+
+	if hasUnavailable {
+		warnings = append(warnings, UnavailableProducts())
+	}
+
+	return &GetBasketResponse{
+		ID:           basket.ID,
+		UserID:       basket.UserID,
+		CreatedAt:    basket.CreatedAt.Format(layoutISO),
+		UpdatedAt:    basket.UpdatedAt.Format(layoutISO),
+		CheckedOutAt: formatCheckedOutAt(basket),
+		Products:     productQuantityPairs,
+		Warnings:     warnings,
+	}
+}
+
+// Localize translates the messages of the warnings to locale.
+func (r *GetBasketResponse) Localize(locale string) {
+	for i := range r.Warnings {
+		r.Warnings[i] = cerr.Localize(r.Warnings[i], locale)
 	}
 }
 
 type ProductQuantityPair struct {
-	Product  *product.Product `json:"product,omitempty"`
-	Quantity int              `json:"quantity"`
+	Product *product.Product `json:"product,omitempty"`
+	// ProductID identifies the line when nothing else is known about its
+	// product, Product is omitted then.
+	ProductID   string       `json:"product_id,omitempty"`
+	Quantity    int          `json:"quantity"`
+	Status      string       `json:"status"`
+	Reason      string       `json:"reason,omitempty"`
+	Removed     bool         `json:"removed,omitempty"`
+	Stale       bool         `json:"stale,omitempty"`
+	PriceChange *PriceChange `json:"price_change,omitempty"`
+}
+
+// PriceChange is reported on a basket line when the current price of the
+// product differs from the price the shopper saw when adding it.
+type PriceChange struct {
+	PreviousPrice float64 `json:"previous_price"`
+	CurrentPrice  float64 `json:"current_price"`
+}
+
+func newPriceChange(snapshot *ProductSnapshot, current *product.Product) *PriceChange {
+	if snapshot == nil || current == nil || minorUnits(snapshot.Price) == minorUnits(current.Price) {
+		return nil
+	}
+
+	return &PriceChange{
+		PreviousPrice: snapshot.Price,
+		CurrentPrice:  current.Price,
+	}
+}
+
+// minorUnits returns price in cents. Prices are compared in cents, the float
+// prices of the same amount may differ in their last bits after a round trip
+// through json or the database.
+func minorUnits(price float64) int64 {
+	return int64(math.Round(price * 100))
+}
+
+func formatCheckedOutAt(basket *Basket) string {
+	if basket.CheckedOutAt == nil {
+		return ""
+	}
+
+	return basket.CheckedOutAt.Format(layoutISO)
+}
+
+// productID returns the id of the product of the line.
+func (p ProductQuantityPair) productID() string {
+	if p.Product == nil {
+		return p.ProductID
+	}
+
+	return p.Product.ID
+}
+
+// newUnavailablePair shows the snapshot of a line whose product is not
+// available. A line without a snapshot has no product data to show, it is
+// not rendered as a product with a zero price.
+func newUnavailablePair(p Product, reason string) ProductQuantityPair {
+	pair := ProductQuantityPair{
+		Product:  newProductFromSnapshot(p),
+		Quantity: p.Quantity,
+		Status:   ProductStatusUnavailable,
+		Reason:   reason,
+	}
+
+	if pair.Product == nil {
+		pair.ProductID = p.ID
+	}
+
+	return pair
+}
+
+func newProductFromSnapshot(p Product) *product.Product {
+	if p.Snapshot == nil {
+		return nil
+	}
+
+	return &product.Product{
+		ID:       p.ID,
+		Name:     p.Snapshot.Name,
+		Code:     p.Snapshot.Code,
+		Price:    p.Snapshot.Price,
+		ImageURL: p.Snapshot.ImageURL,
+		Type:     p.Snapshot.Type,
+	}
 }
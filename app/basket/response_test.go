@@ -0,0 +1,94 @@
+package basket_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/app/product"
+	"github.com/stretchr/testify/suite"
+)
+
+type BasketResponseTestSuite struct {
+	suite.Suite
+}
+
+func TestBasketResponseTestSuite(t *testing.T) {
+	suite.Run(t, new(BasketResponseTestSuite))
+}
+
+func (s *BasketResponseTestSuite) TestGivenProductMissingFromCatalogThenItShouldBeReportedAsUnavailable() {
+	givenBasket := &basket.Basket{
+		ID: "b1",
+		Products: []basket.Product{
+			{ID: "p1", Quantity: 1},
+			{ID: "p2", Quantity: 2, Snapshot: &basket.ProductSnapshot{Name: "old name"}},
+		},
+	}
+
+	resp := basket.NewBasketResponse(givenBasket, []product.Product{{ID: "p1"}})
+
+	s.Equal(basket.ProductStatusAvailable, resp.Products[0].Status)
+	s.Equal(basket.ProductStatusUnavailable, resp.Products[1].Status)
+	s.Equal(basket.UnavailableReasonNotInCatalog, resp.Products[1].Reason)
+	s.Equal("p2", resp.Products[1].Product.ID)
+	s.Equal("old name", resp.Products[1].Product.Name)
+	s.Equal([]int{int(basket.UnavailableProductsWarnCode)}, warningCodes(resp))
+}
+
+func (s *BasketResponseTestSuite) TestGivenPriceChangedSinceAddingThenItShouldBeReported() {
+	givenBasket := &basket.Basket{
+		ID:       "b1",
+		Products: []basket.Product{{ID: "p1", Quantity: 1, Snapshot: &basket.ProductSnapshot{Price: 10}}},
+	}
+
+	resp := basket.NewBasketResponse(givenBasket, []product.Product{{ID: "p1", Price: 12.5}})
+
+	s.Equal(&basket.PriceChange{PreviousPrice: 10, CurrentPrice: 12.5}, resp.Products[0].PriceChange)
+	s.Equal([]int{int(basket.PriceChangedWarnCode)}, warningCodes(resp))
+}
+
+func (s *BasketResponseTestSuite) TestGivenSamePriceWithFloatErrorThenItShouldNotBeReportedAsChanged() {
+	givenBasket := &basket.Basket{
+		ID:       "b1",
+		Products: []basket.Product{{ID: "p1", Quantity: 1, Snapshot: &basket.ProductSnapshot{Price: 0.3}}},
+	}
+
+	// 0.1 + 0.2 is 0.30000000000000004
+	resp := basket.NewBasketResponse(givenBasket, []product.Product{{ID: "p1", Price: 0.1 + 0.2}})
+
+	s.Nil(resp.Products[0].PriceChange)
+	s.Empty(resp.Warnings)
+}
+
+func (s *BasketResponseTestSuite) TestGivenPriceChangedByOneCentThenItShouldBeReported() {
+	givenBasket := &basket.Basket{
+		ID:       "b1",
+		Products: []basket.Product{{ID: "p1", Quantity: 1, Snapshot: &basket.ProductSnapshot{Price: 19.99}}},
+	}
+
+	resp := basket.NewBasketResponse(givenBasket, []product.Product{{ID: "p1", Price: 20}})
+
+	s.Equal(&basket.PriceChange{PreviousPrice: 19.99, CurrentPrice: 20}, resp.Products[0].PriceChange)
+}
+
+func (s *BasketResponseTestSuite) TestGivenStaleBasketThenItShouldServeSnapshots() {
+	givenBasket := &basket.Basket{
+		ID:       "b1",
+		Products: []basket.Product{{ID: "p1", Quantity: 3, Snapshot: &basket.ProductSnapshot{Price: 10}}},
+	}
+
+	resp := basket.NewStaleBasketResponse(givenBasket)
+
+	s.True(resp.Products[0].Stale)
+	s.Equal(10.0, resp.Products[0].Product.Price)
+	s.Equal([]int{int(basket.StaleProductDataWarnCode)}, warningCodes(resp))
+}
+
+func warningCodes(resp *basket.GetBasketResponse) []int {
+	codes := make([]int, len(resp.Warnings))
+	for i, w := range resp.Warnings {
+		codes[i] = int(w.Code)
+	}
+
+	return codes
+}
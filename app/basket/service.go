@@ -4,11 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pact-cdc-example/basket-service/app/product"
 	"github.com/pact-cdc-example/basket-service/app/stock"
 	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
+	"github.com/pact-cdc-example/basket-service/pkg/reqctx"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,13 +20,15 @@ type Service interface {
 	AddProductToBasket(ctx context.Context, req AddProductToBasketRequest) (*GetBasketResponse, error)
 	GetBasketByID(ctx context.Context, basketID string) (*GetBasketResponse, error)
 	AddBulkProductToBasket(ctx context.Context, req AddBulkProductToBasketRequest) (*GetBasketResponse, error)
+	Checkout(ctx context.Context, req CheckoutRequest) (*GetBasketResponse, error)
 }
 
 type service struct {
-	repo          Repository
-	logger        *logrus.Logger
-	productClient product.Client
-	stockClient   stock.Client
+	repo                     Repository
+	logger                   *logrus.Logger
+	productClient            product.Client
+	stockClient              stock.Client
+	pruneUnavailableProducts bool
 }
 
 type NewServiceOpts struct {
@@ -31,14 +36,18 @@ type NewServiceOpts struct {
 	L  *logrus.Logger
 	PC product.Client
 	SC stock.Client
+	// PruneUnavailableProducts removes the lines of products which are no
+	// longer in the catalog when the basket is served.
+	PruneUnavailableProducts bool
 }
 
 func NewService(opts *NewServiceOpts) Service {
 	return &service{
-		repo:          opts.R,
-		logger:        opts.L,
-		productClient: opts.PC,
-		stockClient:   opts.SC,
+		repo:                     opts.R,
+		logger:                   opts.L,
+		productClient:            opts.PC,
+		stockClient:              opts.SC,
+		pruneUnavailableProducts: opts.PruneUnavailableProducts,
 	}
 }
 
@@ -46,15 +55,26 @@ func (s *service) CreateBasket(
 	ctx context.Context, req CreateBasketRequest) (*GetBasketResponse, error) {
 	basketID := uuid.New().String()
 
-	basket, err := s.repo.CreateBasket(ctx, &Basket{
-		ID:     basketID,
-		UserID: req.UserID,
+	var basket *Basket
+	err := s.repo.RunInTx(ctx, func(repo Repository) error {
+		var err error
+		basket, err = repo.CreateBasket(ctx, &Basket{
+			ID:     basketID,
+			UserID: req.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return publishEvent(ctx, repo, basket.ID, newBasketCreatedEvent(basket))
 	})
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		s.logger.Errorf("could not create basket: %v", err)
-		return nil, cerr.Processing()
+	if err != nil {
+		s.log(ctx).Errorf("could not create basket: %v", err)
+		return nil, cerr.Processing().Wrap(err)
 	}
 
+	basketsCreated.Inc()
+
 	return NewBasketResponse(basket, nil), nil
 }
 
@@ -62,101 +82,400 @@ func (s *service) AddProductToBasket(
 	ctx context.Context, req AddProductToBasketRequest) (*GetBasketResponse, error) {
 	basket, err := s.repo.GetBasketByID(ctx, req.BasketID)
 	if err != nil || basket == nil || basket.UserID != req.UserID {
-		s.logger.WithField("basket_id", req.BasketID).Errorf("could not found basket: %v", err)
-		return nil, cerr.Bag{Code: BasketNotFoundErrCode, Message: "basket not found"}
+		s.log(ctx).WithField("basket_id", req.BasketID).Errorf("could not found basket: %v", err)
+		return nil, BasketNotFound()
+	}
+
+	if basket.CheckedOutAt != nil {
+		return nil, BasketCheckedOut()
 	}
 
-	_, err = s.getProductByID(ctx, req.ProductID)
+	prod, err := s.getProductByID(ctx, req.ProductID)
 	if err != nil {
-		return nil, cerr.Processing()
+		return nil, err
 	}
 
 	isAvailableInStock, err := s.isProductAvailableInStockInDesiredQuantity(ctx, req.ProductID, req.Quantity)
 	if err != nil {
-		return nil, cerr.Processing()
+		return nil, err
 	}
 
 	if !isAvailableInStock {
-		return nil, cerr.Bag{Code: ProductNotHasEnoughStockErrCode, Message: "Product not has enough stock."}
+		outOfStockRejections.Inc()
+		return nil, ProductNotHasEnoughStock()
 	}
 
-	basket, err = s.repo.AddProductToBasket(ctx, &Product{
-		ID:       req.ProductID,
-		Quantity: req.Quantity,
-		BasketID: basket.ID,
+	err = s.repo.RunInTx(ctx, func(repo Repository) error {
+		line := &Product{
+			ID:       req.ProductID,
+			Quantity: req.Quantity,
+			BasketID: basket.ID,
+			Snapshot: newProductSnapshot(prod),
+		}
+
+		var err error
+		if basket, err = repo.AddProductToBasket(ctx, line); err != nil {
+			return err
+		}
+
+		if err = publishEvent(ctx, repo, basket.ID, newItemAddedEvent(basket, line, time.Now().UTC())); err != nil {
+			return err
+		}
+
+		return reserveStock(ctx, repo, &Reservation{
+			ID:        uuid.New().String(),
+			BasketID:  basket.ID,
+			ProductID: req.ProductID,
+			Quantity:  req.Quantity,
+			Status:    ReservationStatusPending,
+		})
 	})
 	if err != nil {
-		s.logger.WithField("basket_id", req.BasketID).Errorf("could not add product to basket: %v", err)
-		return nil, cerr.Processing()
+		s.log(ctx).WithField("basket_id", req.BasketID).Errorf("could not add product to basket: %v", err)
+		return nil, cerr.Processing().Wrap(err)
 	}
 
-	_, err = s.stockClient.ReserveStock(ctx, stock.ReserveStockRequest{
-		ProductID: req.ProductID,
-		Quantity:  req.Quantity,
-	})
+	itemsAdded.Inc()
+
+	return s.newBasketResponse(ctx, basket)
+}
+
+func (s *service) GetBasketByID(
+	ctx context.Context, basketID string) (*GetBasketResponse, error) {
+	basket, err := s.repo.GetBasketByID(ctx, basketID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, BasketNotFound()
+	}
 	if err != nil {
-		s.logger.WithField("product_id", req.ProductID).WithField("quantity", req.Quantity).
-			WithField("basket_id", req.BasketID).Errorf("could not reserve stock: %v", err)
-		return nil, cerr.Processing()
+		s.log(ctx).WithField("basket_id", basketID).Errorf("could not found basket: %v", err)
+		return nil, cerr.Processing().Wrap(err)
 	}
 
-	productIDs := getIDsOfProducts(basket.Products)
+	return s.newBasketResponse(ctx, basket)
+}
 
-	products, err := s.getProductsByIDs(ctx, productIDs)
+func (s *service) AddBulkProductToBasket(
+	ctx context.Context, req AddBulkProductToBasketRequest) (*GetBasketResponse, error) {
+	basket, err := s.repo.GetBasketByID(ctx, req.BasketID)
+	if err != nil || basket == nil || basket.UserID != req.UserID {
+		s.log(ctx).WithField("basket_id", req.BasketID).Errorf("could not found basket: %v", err)
+		return nil, BasketNotFound()
+	}
+
+	if basket.CheckedOutAt != nil {
+		return nil, BasketCheckedOut()
+	}
+
+	isAvailableInStock, err := s.areProductsAvailableInStock(ctx, req.Products)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewBasketResponse(basket, products), nil
+	if !isAvailableInStock {
+		outOfStockRejections.Inc()
+		return nil, ProductNotHasEnoughStock()
+	}
+
+	snapshots := s.getProductSnapshots(ctx, req.Products)
+
+	err = s.repo.RunInTx(ctx, func(repo Repository) error {
+		for _, prod := range req.Products {
+			line := &Product{
+				ID:       prod.ID,
+				Quantity: prod.Quantity,
+				BasketID: basket.ID,
+				Snapshot: snapshots[prod.ID],
+			}
+
+			if _, err := repo.AddProductToBasket(ctx, line); err != nil {
+				return err
+			}
+
+			err := publishEvent(ctx, repo, basket.ID, newItemAddedEvent(basket, line, time.Now().UTC()))
+			if err != nil {
+				return err
+			}
+
+			if err = reserveStock(ctx, repo, &Reservation{
+				ID:        uuid.New().String(),
+				BasketID:  basket.ID,
+				ProductID: prod.ID,
+				Quantity:  prod.Quantity,
+				Status:    ReservationStatusPending,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.log(ctx).WithField("basket_id", req.BasketID).Errorf("could not add products to basket: %v", err)
+		return nil, cerr.Processing().Wrap(err)
+	}
+
+	itemsAdded.Add(float64(len(req.Products)))
+
+	return s.GetBasketByID(ctx, basket.ID)
 }
 
-func (s *service) GetBasketByID(
-	ctx context.Context, basketID string) (*GetBasketResponse, error) {
-	basket, err := s.repo.GetBasketByID(ctx, basketID)
+// Checkout closes the basket and commits the stock reserved for it. It fails
+// while a reservation is not delivered to the stock service yet.
+func (s *service) Checkout(
+	ctx context.Context, req CheckoutRequest) (*GetBasketResponse, error) {
+	basket, err := s.repo.GetBasketByID(ctx, req.BasketID)
+	if err != nil || basket == nil || basket.UserID != req.UserID {
+		s.log(ctx).WithField("basket_id", req.BasketID).Errorf("could not found basket: %v", err)
+		return nil, BasketNotFound()
+	}
+
+	if basket.CheckedOutAt != nil {
+		return nil, BasketCheckedOut()
+	}
+
+	// the basket is checked out before its reservations are read, so a
+	// concurrent checkout waits for this one and fails instead of committing
+	// the stock twice.
+	err = s.repo.RunInTx(ctx, func(repo Repository) error {
+		var err error
+		if basket, err = repo.CheckoutBasket(ctx, basket.ID); err != nil {
+			return err
+		}
+
+		// the reservations are locked so the expirer can not release them
+		// while they are committed.
+		reservations, err := repo.LockReservationsByBasketID(ctx, basket.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, reservation := range reservations {
+			if reservation.Status == ReservationStatusPending {
+				return ReservationsPending()
+			}
+		}
+
+		for i := range reservations {
+			reservation := &reservations[i]
+			if reservation.Status != ReservationStatusReserved {
+				continue
+			}
+
+			committed, err := repo.UpdateReservationStatus(
+				ctx, reservation.ID, ReservationStatusReserved, ReservationStatusCommitted)
+			if err != nil {
+				return err
+			}
+			if !committed {
+				continue
+			}
+
+			commitStock, err := newStockCommandMessage(CommandCommitStock, reservation)
+			if err != nil {
+				return err
+			}
+
+			if err = repo.CreateOutboxMessage(ctx, commitStock); err != nil {
+				return err
+			}
+		}
+
+		return publishEvent(ctx, repo, basket.ID, newCheckedOutEvent(basket))
+	})
+
+	var bag cerr.Bag
+	if errors.As(err, &bag) && (bag.Code == BasketCheckedOutErrCode || bag.Code == ReservationsPendingErrCode) {
+		return nil, bag
+	}
 	if err != nil {
-		s.logger.WithField("basket_id", basketID).Errorf("could not found basket: %v", err)
-		return nil, cerr.Processing()
+		s.log(ctx).WithField("basket_id", req.BasketID).Errorf("could not checkout basket: %v", err)
+		return nil, cerr.Processing().Wrap(err)
+	}
+
+	checkouts.Inc()
+
+	return s.newBasketResponse(ctx, basket)
+}
+
+// reserveStock writes a pending reservation to the ledger together with the
+// command which makes the outbox relay reserve it.
+func reserveStock(ctx context.Context, repo Repository, reservation *Reservation) error {
+	message, err := newStockCommandMessage(CommandReserveStock, reservation)
+	if err != nil {
+		return err
+	}
+
+	if err = repo.CreateReservation(ctx, reservation); err != nil {
+		return err
+	}
+
+	return repo.CreateOutboxMessage(ctx, message)
+}
+
+// log returns the logger of the request ctx belongs to.
+func (s *service) log(ctx context.Context) *logrus.Entry {
+	return reqctx.Logger(ctx, s.logger)
+}
+
+// newBasketResponse joins the basket with the current product data. When the
+// product api is not available the basket is still served, from the product
+// snapshots. Other failures, such as the product api rejecting the request,
+// are not hidden behind stale data.
+func (s *service) newBasketResponse(ctx context.Context, basket *Basket) (*GetBasketResponse, error) {
+	if len(basket.Products) == 0 {
+		return NewBasketResponse(basket, nil), nil
 	}
 
 	productIDs := getIDsOfProducts(basket.Products)
 
 	products, err := s.getProductsByIDs(ctx, productIDs)
+	if product.IsNotFound(err) {
+		products, err = s.getAvailableProducts(ctx, productIDs)
+	}
 	if err != nil {
-		return nil, err
+		if cerr.ClassOf(err) != cerr.ClassDependency {
+			return nil, err
+		}
+
+		s.log(ctx).WithField("basket_id", basket.ID).Warn("serving basket with stale product data")
+		return NewStaleBasketResponse(basket), nil
+	}
+
+	resp := NewBasketResponse(basket, products)
+	if s.pruneUnavailableProducts {
+		s.removeUnavailableProducts(ctx, resp)
 	}
 
-	return NewBasketResponse(basket, products), nil
+	return resp, nil
 }
 
-func (s *service) AddBulkProductToBasket(
-	ctx context.Context, req AddBulkProductToBasketRequest) (*GetBasketResponse, error) {
-	basket, err := s.repo.GetBasketByID(ctx, req.BasketID)
-	if err != nil || basket == nil || basket.UserID != req.UserID {
-		s.logger.WithField("basket_id", req.BasketID).Errorf("could not found basket: %v", err)
-		return nil, cerr.Bag{Code: BasketNotFoundErrCode, Message: "basket not found"}
+// getAvailableProducts looks the products up in two halves, since the bulk
+// endpoint fails as a whole when one of them is not in the catalog. The halves
+// which fail again are split further, until the products which are not in
+// the catalog are left out one by one.
+func (s *service) getAvailableProducts(ctx context.Context, productIDs []string) ([]product.Product, error) {
+	if len(productIDs) == 1 {
+		s.log(ctx).WithField("product_id", productIDs[0]).Warn("product is not in the catalog anymore")
+		return nil, nil
 	}
 
-	for _, prod := range req.Products {
-		_, err := s.repo.AddProductToBasket(ctx, &Product{
-			ID:       prod.ID,
-			Quantity: prod.Quantity,
-			BasketID: basket.ID,
-		})
+	mid := len(productIDs) / 2
+
+	var products []product.Product
+	for _, ids := range [][]string{productIDs[:mid], productIDs[mid:]} {
+		found, err := s.getProductsByIDs(ctx, ids)
+		if product.IsNotFound(err) {
+			found, err = s.getAvailableProducts(ctx, ids)
+		}
 		if err != nil {
-			s.logger.WithField("basket_id", req.BasketID).
-				WithField("product_id", prod.ID).Errorf("could not add product to basket: %v", err)
-			return nil, cerr.Processing()
+			return nil, err
 		}
+
+		products = append(products, found...)
 	}
 
-	return s.GetBasketByID(ctx, basket.ID)
+	return products, nil
+}
+
+func (s *service) removeUnavailableProducts(ctx context.Context, resp *GetBasketResponse) {
+	for i := range resp.Products {
+		pair := &resp.Products[i]
+		if pair.Status != ProductStatusUnavailable {
+			continue
+		}
+
+		productID := pair.productID()
+		if err := s.removeProductFromBasket(ctx, resp.ID, productID); err != nil {
+			s.log(ctx).WithField("basket_id", resp.ID).WithField("product_id", productID).
+				Errorf("could not remove unavailable product from basket: %v", err)
+			continue
+		}
+
+		pair.Removed = true
+	}
+}
+
+// removeProductFromBasket removes the line and releases its reservations in
+// the same transaction. The reservations are read in the transaction and
+// released only from the status they are read in, so a reservation confirmed
+// by the relay meanwhile has its stock released instead of leaking it.
+func (s *service) removeProductFromBasket(ctx context.Context, basketID string, productID string) error {
+	return s.repo.RunInTx(ctx, func(repo Repository) error {
+		if _, err := repo.RemoveProductFromBasket(ctx, basketID, productID); err != nil {
+			return err
+		}
+
+		reservations, err := repo.LockReservationsByBasketID(ctx, basketID)
+		if err != nil {
+			return err
+		}
+
+		for i := range reservations {
+			reservation := &reservations[i]
+			if reservation.ProductID != productID || !reservation.isActive() {
+				continue
+			}
+
+			released, err := repo.UpdateReservationStatus(
+				ctx, reservation.ID, reservation.Status, ReservationStatusReleased)
+			if err != nil {
+				return err
+			}
+
+			// a pending reservation is skipped by the relay once released
+			if !released || reservation.Status == ReservationStatusPending {
+				continue
+			}
+
+			releaseStock, err := newStockCommandMessage(CommandReleaseStock, reservation)
+			if err != nil {
+				return err
+			}
+
+			if err = repo.CreateOutboxMessage(ctx, releaseStock); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// getProductSnapshots returns the snapshots of the given products by their ids.
+// Snapshots are best effort, the products are added without them when the
+// product api fails.
+func (s *service) getProductSnapshots(
+	ctx context.Context, products []BulkProduct) map[string]*ProductSnapshot {
+	productIDs := make([]string, len(products))
+	for i, prod := range products {
+		productIDs[i] = prod.ID
+	}
+
+	snapshots := make(map[string]*ProductSnapshot, len(products))
+	if len(productIDs) == 0 {
+		return snapshots
+	}
+
+	found, err := s.getProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return snapshots
+	}
+
+	for i := range found {
+		snapshots[found[i].ID] = newProductSnapshot(&found[i])
+	}
+
+	return snapshots
 }
 
 func (s *service) getProductByID(ctx context.Context, productID string) (*product.Product, error) {
 	prod, err := s.productClient.GetProductByID(ctx, productID)
 	if err != nil {
-		s.logger.WithField("product_id", productID).Errorf("could not get product from product service: %v", err)
-		return nil, cerr.Processing()
+		if product.IsNotFound(err) {
+			return nil, product.ProductNotFound().Wrap(err)
+		}
+		s.log(ctx).WithField("product_id", productID).Errorf("could not get product from product service: %v", err)
+		return nil, productAPIError(err)
 	}
 
 	return prod, nil
@@ -167,13 +486,56 @@ func (s *service) getProductsByIDs(ctx context.Context, productIDs []string) ([]
 		IDs: productIDs,
 	})
 	if err != nil {
-		s.logger.Errorf("could not get products from product api: %v", err)
-		return nil, cerr.Processing()
+		if product.IsNotFound(err) {
+			return nil, err
+		}
+		s.log(ctx).Errorf("could not get products from product api: %v", err)
+		return nil, productAPIError(err)
 	}
 
 	return products, nil
 }
 
+// productAPIError reports the product api as unavailable only when it failed
+// to answer, a request it rejected is a bug of the basket service.
+func productAPIError(err error) error {
+	if httpclient.IsServerError(err) {
+		return cerr.DependencyUnavailable().Wrap(err)
+	}
+
+	return cerr.Processing().Wrap(err)
+}
+
+func (s *service) areProductsAvailableInStock(ctx context.Context, products []BulkProduct) (bool, error) {
+	if len(products) == 0 {
+		return true, nil
+	}
+
+	req := stock.CheckAvailabilityRequest{Products: make([]stock.ProductQuantity, len(products))}
+	for i, prod := range products {
+		req.Products[i] = stock.ProductQuantity{ProductID: prod.ID, Quantity: prod.Quantity}
+	}
+
+	availabilities, err := s.stockClient.CheckAvailability(ctx, req)
+	if err != nil {
+		s.log(ctx).Errorf("could not check products availability in stock: %v", err)
+		return false, cerr.DependencyUnavailable().Wrap(err)
+	}
+
+	available := make(map[string]bool, len(availabilities))
+	for _, availability := range availabilities {
+		available[availability.ProductID] = availability.IsAvailable
+	}
+
+	for _, prod := range products {
+		if !available[prod.ID] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
 func (s *service) isProductAvailableInStockInDesiredQuantity(
 	ctx context.Context, productID string, quantity int) (bool, error) {
 	isAvailable, err := s.stockClient.IsProductAvailableInStock(ctx, stock.IsProductAvailableInStockRequest{
@@ -181,8 +543,8 @@ func (s *service) isProductAvailableInStockInDesiredQuantity(
 		Quantity:  &quantity,
 	})
 	if err != nil {
-		s.logger.WithField("product_id", productID).Errorf("could not check product availability in stock: %v", err)
-		return false, cerr.Processing()
+		s.log(ctx).WithField("product_id", productID).Errorf("could not check product availability in stock: %v", err)
+		return false, cerr.DependencyUnavailable().Wrap(err)
 	}
 
 	return isAvailable, nil
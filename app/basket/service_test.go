@@ -0,0 +1,734 @@
+package basket_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/app/basket/events"
+	"github.com/pact-cdc-example/basket-service/app/internal/persistencetest"
+	"github.com/pact-cdc-example/basket-service/app/product"
+	"github.com/pact-cdc-example/basket-service/app/stock"
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeProductClient struct {
+	mu       sync.Mutex
+	products map[string]product.Product
+	// err is returned by every lookup when set, or by the lookups after
+	// the first failAfter ones.
+	err       error
+	failAfter int
+	singles   []string
+	bulks     [][]string
+}
+
+func (c *fakeProductClient) GetProductByID(_ context.Context, id string) (*product.Product, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.singles = append(c.singles, id)
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	prod, ok := c.products[id]
+	if !ok {
+		return nil, product.ProductNotFound()
+	}
+
+	return &prod, nil
+}
+
+func (c *fakeProductClient) GetProductsByIDs(
+	_ context.Context, req product.GetProductByIDsRequest) ([]product.Product, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.bulks = append(c.bulks, req.IDs)
+	if c.failAfter > 0 && len(c.bulks) > c.failAfter {
+		return nil, errors.New("connection refused")
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	products := make([]product.Product, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		prod, ok := c.products[id]
+		if !ok {
+			return nil, product.SomeProductsNotFound()
+		}
+		products = append(products, prod)
+	}
+
+	return products, nil
+}
+
+type fakeStockClient struct {
+	mu          sync.Mutex
+	unavailable bool
+	// stocks are answered by GetStockByProductID, the products without one
+	// are answered with errStockNotFound.
+	stocks map[string]stock.Stock
+	// reserveErr fails the reservations when set, onReserve runs while a
+	// reservation is in flight.
+	reserveErr error
+	onReserve  func()
+	reserved   []stock.ReserveStockRequest
+	released   []stock.ReleaseStockRequest
+	committed  []stock.CommitReservationRequest
+}
+
+var errStockNotFound = errors.New("stock not found")
+
+func (c *fakeStockClient) IsProductAvailableInStock(
+	_ context.Context, _ stock.IsProductAvailableInStockRequest) (bool, error) {
+	return !c.unavailable, nil
+}
+
+func (c *fakeStockClient) ReserveStock(
+	_ context.Context, req stock.ReserveStockRequest) (*stock.Stock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reserved = append(c.reserved, req)
+	if c.onReserve != nil {
+		c.onReserve()
+	}
+	if c.reserveErr != nil {
+		return nil, c.reserveErr
+	}
+
+	return &stock.Stock{ID: "s-" + req.ProductID, ProductID: req.ProductID, ReservedQuantity: req.Quantity}, nil
+}
+
+func (c *fakeStockClient) GetStockByProductID(
+	_ context.Context, productID string) (*stock.Stock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	productStock, ok := c.stocks[productID]
+	if !ok {
+		return nil, errStockNotFound
+	}
+
+	return &productStock, nil
+}
+
+func (c *fakeStockClient) ReleaseStock(
+	_ context.Context, req stock.ReleaseStockRequest) (*stock.Stock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.released = append(c.released, req)
+
+	return &stock.Stock{ID: req.StockID, ProductID: req.ProductID}, nil
+}
+
+func (c *fakeStockClient) CommitReservation(
+	_ context.Context, req stock.CommitReservationRequest) (*stock.Stock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.committed = append(c.committed, req)
+
+	return &stock.Stock{ID: req.StockID, ProductID: req.ProductID}, nil
+}
+
+func (c *fakeStockClient) CheckAvailability(
+	_ context.Context, req stock.CheckAvailabilityRequest) ([]stock.ProductAvailability, error) {
+	availabilities := make([]stock.ProductAvailability, len(req.Products))
+	for i, prod := range req.Products {
+		availabilities[i] = stock.ProductAvailability{ProductID: prod.ProductID, IsAvailable: !c.unavailable}
+	}
+
+	return availabilities, nil
+}
+
+// recordingPublisher keeps the messages the outbox relay publishes.
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages []events.Message
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, message *events.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = append(p.messages, *message)
+
+	return nil
+}
+
+const (
+	basketsCreatedSeries = "basket_service_basket_baskets_created_total"
+	itemsAddedSeries     = "basket_service_basket_items_added_total"
+	outOfStockSeries     = "basket_service_basket_out_of_stock_rejections_total"
+	checkoutsSeries      = "basket_service_basket_checkouts_total"
+)
+
+type BasketServiceTestSuite struct {
+	suite.Suite
+	repo          persistencetest.MemoryRepository
+	productClient *fakeProductClient
+	stockClient   *fakeStockClient
+	publisher     *recordingPublisher
+	service       basket.Service
+}
+
+func TestBasketServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(BasketServiceTestSuite))
+}
+
+func (s *BasketServiceTestSuite) SetupTest() {
+	s.repo = persistencetest.NewMemoryRepository()
+	s.productClient = &fakeProductClient{products: map[string]product.Product{
+		"p1": {ID: "p1", Name: "shoe", Price: 10},
+		"p2": {ID: "p2", Name: "sock", Price: 2},
+		"p3": {ID: "p3", Name: "hat", Price: 7},
+		"p4": {ID: "p4", Name: "belt", Price: 5},
+	}}
+	s.stockClient = &fakeStockClient{}
+	s.publisher = &recordingPublisher{}
+	s.service = s.newService(false)
+}
+
+func (s *BasketServiceTestSuite) newService(pruneUnavailableProducts bool) basket.Service {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	return basket.NewService(&basket.NewServiceOpts{
+		R:                        s.repo,
+		L:                        logger,
+		PC:                       s.productClient,
+		SC:                       s.stockClient,
+		PruneUnavailableProducts: pruneUnavailableProducts,
+	})
+}
+
+func (s *BasketServiceTestSuite) TestGivenProductAPIDownThenBasketShouldBeServedFromSnapshots() {
+	s.givenBasket("b1", basket.Product{ID: "p1", Quantity: 2, Snapshot: &basket.ProductSnapshot{Name: "shoe", Price: 10}})
+	s.productClient.err = errors.New("connection refused")
+
+	resp, err := s.service.GetBasketByID(context.Background(), "b1")
+
+	s.Require().Nil(err)
+	s.Equal([]int{int(basket.StaleProductDataWarnCode)}, warningCodes(resp))
+	s.Equal("shoe", resp.Products[0].Product.Name)
+	s.True(resp.Products[0].Stale)
+}
+
+func (s *BasketServiceTestSuite) TestGivenProductAPIFailingWithServerErrorThenBasketShouldBeServedFromSnapshots() {
+	s.givenBasket("b1", basket.Product{ID: "p1", Quantity: 2, Snapshot: &basket.ProductSnapshot{Name: "shoe", Price: 10}})
+	s.productClient.err = &httpclient.ResponseError{StatusCode: http.StatusBadGateway, Bag: cerr.Bag{Message: "bad gateway"}}
+
+	resp, err := s.service.GetBasketByID(context.Background(), "b1")
+
+	s.Require().Nil(err)
+	s.True(resp.Products[0].Stale)
+}
+
+func (s *BasketServiceTestSuite) TestGivenProductAPIRejectingRequestThenItShouldNotBeHiddenBehindStaleData() {
+	s.givenBasket("b1", basket.Product{ID: "p1", Quantity: 2, Snapshot: &basket.ProductSnapshot{Name: "shoe", Price: 10}})
+	s.productClient.err = &httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Message: "bad request"}}
+
+	resp, err := s.service.GetBasketByID(context.Background(), "b1")
+
+	s.Nil(resp)
+	s.NotNil(err)
+	s.NotEqual(cerr.ClassDependency, cerr.ClassOf(err))
+}
+
+func (s *BasketServiceTestSuite) TestGivenLegacyLineWithoutSnapshotAndProductAPIDownThenItShouldBeUnavailable() {
+	s.givenBasket("b1",
+		basket.Product{ID: "p1", Quantity: 1, Snapshot: &basket.ProductSnapshot{Name: "shoe", Price: 10}},
+		basket.Product{ID: "p2", Quantity: 3},
+	)
+	s.productClient.err = errors.New("connection refused")
+
+	resp, err := s.service.GetBasketByID(context.Background(), "b1")
+
+	s.Require().Nil(err)
+	s.Equal([]int{int(basket.StaleProductDataWarnCode), int(basket.UnavailableProductsWarnCode)}, warningCodes(resp))
+
+	legacy := resp.Products[1]
+	s.Nil(legacy.Product)
+	s.Equal("p2", legacy.ProductID)
+	s.Equal(3, legacy.Quantity)
+	s.Equal(basket.ProductStatusUnavailable, legacy.Status)
+	s.Equal(basket.UnavailableReasonNoSnapshot, legacy.Reason)
+}
+
+func (s *BasketServiceTestSuite) TestGivenProductsMissingFromCatalogThenOthersShouldBeLookedUpInBulk() {
+	s.givenBasket("b1",
+		basket.Product{ID: "p1", Quantity: 1},
+		basket.Product{ID: "p2", Quantity: 1},
+		basket.Product{ID: "p3", Quantity: 1},
+		basket.Product{ID: "p4", Quantity: 1},
+	)
+	delete(s.productClient.products, "p2")
+
+	resp, err := s.service.GetBasketByID(context.Background(), "b1")
+
+	s.Require().Nil(err)
+	s.Equal(basket.ProductStatusAvailable, resp.Products[0].Status)
+	s.Equal(basket.ProductStatusUnavailable, resp.Products[1].Status)
+	s.Equal(basket.UnavailableReasonNotInCatalog, resp.Products[1].Reason)
+	s.Equal(basket.ProductStatusAvailable, resp.Products[2].Status)
+	s.Equal(basket.ProductStatusAvailable, resp.Products[3].Status)
+	s.Empty(s.productClient.singles)
+	s.Equal([][]string{{"p1", "p2", "p3", "p4"}, {"p1", "p2"}, {"p1"}, {"p2"}, {"p3", "p4"}}, s.productClient.bulks)
+}
+
+func (s *BasketServiceTestSuite) TestGivenProductAPIDownWhileNarrowingDownMissingProductsThenStaleBasketShouldBeServed() {
+	s.givenBasket("b1",
+		basket.Product{ID: "p1", Quantity: 1, Snapshot: &basket.ProductSnapshot{Name: "shoe"}},
+		basket.Product{ID: "p2", Quantity: 1, Snapshot: &basket.ProductSnapshot{Name: "sock"}},
+	)
+	delete(s.productClient.products, "p2")
+	s.productClient.failAfter = 1
+
+	resp, err := s.service.GetBasketByID(context.Background(), "b1")
+
+	s.Require().Nil(err)
+	s.Equal([]int{int(basket.StaleProductDataWarnCode)}, warningCodes(resp))
+}
+
+func (s *BasketServiceTestSuite) TestGivenPruningAndProductMissingFromCatalogThenItShouldBeRemovedOnGet() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+	s.service = s.newService(true)
+
+	for _, productID := range []string{"p1", "p2"} {
+		_, err := s.service.AddProductToBasket(ctx, basket.AddProductToBasketRequest{
+			BasketID: "b1", UserID: "u1", ProductID: productID, Quantity: 1,
+		})
+		s.Require().Nil(err)
+	}
+	delete(s.productClient.products, "p2")
+
+	resp, err := s.service.GetBasketByID(ctx, "b1")
+
+	s.Require().Nil(err)
+	s.True(resp.Products[1].Removed)
+
+	stored, err := s.repo.GetBasketByID(ctx, "b1")
+	s.Require().Nil(err)
+	s.Len(stored.Products, 1)
+	s.Equal("p1", stored.Products[0].ID)
+
+	reservations, err := s.repo.GetReservationsByBasketID(ctx, "b1")
+	s.Require().Nil(err)
+	for _, reservation := range reservations {
+		if reservation.ProductID == "p2" {
+			s.Equal(basket.ReservationStatusReleased, reservation.Status)
+		} else {
+			s.Equal(basket.ReservationStatusPending, reservation.Status)
+		}
+	}
+}
+
+func (s *BasketServiceTestSuite) TestGivenReservationConfirmedWhilePruningThenItsStockShouldBeReleased() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+	_, err := s.service.AddProductToBasket(ctx, basket.AddProductToBasketRequest{
+		BasketID: "b1", UserID: "u1", ProductID: "p1", Quantity: 1,
+	})
+	s.Require().Nil(err)
+	delete(s.productClient.products, "p1")
+
+	// the relay confirms the pending reservation right before the line is removed
+	s.repo = &relayOnTxRepository{MemoryRepository: s.repo, onTx: func() {
+		s.Require().Nil(s.newRelay().Relay(ctx))
+	}}
+	s.service = s.newService(true)
+
+	_, err = s.service.GetBasketByID(ctx, "b1")
+	s.Require().Nil(err)
+
+	s.Require().Nil(s.newRelay().Relay(ctx))
+	s.Equal([]stock.ReleaseStockRequest{{StockID: "s-p1", ProductID: "p1", Quantity: 1}}, s.stockClient.released)
+}
+
+func (s *BasketServiceTestSuite) TestGivenNoPruningAndProductMissingFromCatalogThenItShouldBeKept() {
+	s.givenBasket("b1", basket.Product{ID: "p1", Quantity: 1}, basket.Product{ID: "p2", Quantity: 1})
+	delete(s.productClient.products, "p2")
+
+	resp, err := s.service.GetBasketByID(context.Background(), "b1")
+
+	s.Require().Nil(err)
+	s.False(resp.Products[1].Removed)
+
+	stored, err := s.repo.GetBasketByID(context.Background(), "b1")
+	s.Require().Nil(err)
+	s.Len(stored.Products, 2)
+}
+
+func (s *BasketServiceTestSuite) TestGivenBulkProductsThenTheyShouldBeAddedWithSnapshotsAndPendingReservations() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+
+	resp, err := s.service.AddBulkProductToBasket(ctx, basket.AddBulkProductToBasketRequest{
+		UserID: "u1", BasketID: "b1",
+		Products: []basket.BulkProduct{{ID: "p1", Quantity: 2}, {ID: "p2", Quantity: 1}},
+	})
+
+	s.Require().Nil(err)
+	s.Len(resp.Products, 2)
+
+	stored, err := s.repo.GetBasketByID(ctx, "b1")
+	s.Require().Nil(err)
+	s.Equal(&basket.ProductSnapshot{Name: "shoe", Price: 10}, stored.Products[0].Snapshot)
+	s.Equal(&basket.ProductSnapshot{Name: "sock", Price: 2}, stored.Products[1].Snapshot)
+
+	reservations, err := s.repo.GetReservationsByBasketID(ctx, "b1")
+	s.Require().Nil(err)
+	s.Len(reservations, 2)
+	for _, reservation := range reservations {
+		s.Equal(basket.ReservationStatusPending, reservation.Status)
+	}
+}
+
+func (s *BasketServiceTestSuite) TestGivenBulkProductsOutOfStockThenNoneShouldBeAdded() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+	s.stockClient.unavailable = true
+
+	_, err := s.service.AddBulkProductToBasket(ctx, basket.AddBulkProductToBasketRequest{
+		UserID: "u1", BasketID: "b1",
+		Products: []basket.BulkProduct{{ID: "p1", Quantity: 2}, {ID: "p2", Quantity: 1}},
+	})
+
+	s.Equal(basket.ProductNotHasEnoughStock(), err)
+
+	stored, err := s.repo.GetBasketByID(ctx, "b1")
+	s.Require().Nil(err)
+	s.Empty(stored.Products)
+}
+
+func (s *BasketServiceTestSuite) TestGivenProductAPIDownThenBulkProductsShouldBeAddedWithoutSnapshots() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+	s.productClient.err = errors.New("connection refused")
+
+	_, err := s.service.AddBulkProductToBasket(ctx, basket.AddBulkProductToBasketRequest{
+		UserID: "u1", BasketID: "b1",
+		Products: []basket.BulkProduct{{ID: "p1", Quantity: 2}},
+	})
+
+	s.Require().Nil(err)
+
+	stored, err := s.repo.GetBasketByID(ctx, "b1")
+	s.Require().Nil(err)
+	s.Nil(stored.Products[0].Snapshot)
+}
+
+func (s *BasketServiceTestSuite) TestGivenReservedBasketThenCheckoutShouldCommitItsStock() {
+	ctx := context.Background()
+	s.givenReservedBasket("b1", "p1", "p2")
+
+	resp, err := s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: "b1", UserID: "u1"})
+
+	s.Require().Nil(err)
+	s.NotEmpty(resp.CheckedOutAt)
+
+	reservations, err := s.repo.GetReservationsByBasketID(ctx, "b1")
+	s.Require().Nil(err)
+	for _, reservation := range reservations {
+		s.Equal(basket.ReservationStatusCommitted, reservation.Status)
+	}
+
+	s.Require().Nil(s.newRelay().Relay(ctx))
+	s.ElementsMatch([]stock.CommitReservationRequest{
+		{StockID: "s-p1", ProductID: "p1", Quantity: 1},
+		{StockID: "s-p2", ProductID: "p2", Quantity: 1},
+	}, s.stockClient.committed)
+}
+
+func (s *BasketServiceTestSuite) TestGivenPendingReservationThenCheckoutShouldFailAndKeepBasketOpen() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+	_, err := s.service.AddProductToBasket(ctx, basket.AddProductToBasketRequest{
+		BasketID: "b1", UserID: "u1", ProductID: "p1", Quantity: 1,
+	})
+	s.Require().Nil(err)
+
+	_, err = s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: "b1", UserID: "u1"})
+
+	s.Equal(basket.ReservationsPending(), err)
+
+	stored, err := s.repo.GetBasketByID(ctx, "b1")
+	s.Require().Nil(err)
+	s.Nil(stored.CheckedOutAt)
+}
+
+func (s *BasketServiceTestSuite) TestGivenCheckedOutBasketThenCheckoutShouldFailWithoutCommittingAgain() {
+	ctx := context.Background()
+	s.givenReservedBasket("b1", "p1")
+
+	_, err := s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: "b1", UserID: "u1"})
+	s.Require().Nil(err)
+
+	_, err = s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: "b1", UserID: "u1"})
+	s.Equal(basket.BasketCheckedOut(), err)
+
+	s.Require().Nil(s.newRelay().Relay(ctx))
+	s.Len(s.stockClient.committed, 1)
+}
+
+func (s *BasketServiceTestSuite) TestGivenConcurrentCheckoutsThenOnlyOneShouldCommit() {
+	ctx := context.Background()
+	s.givenReservedBasket("b1", "p1")
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: "b1", UserID: "u1"})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		s.Equal(basket.BasketCheckedOut(), err)
+	}
+	s.Equal(1, succeeded)
+
+	s.Require().Nil(s.newRelay().Relay(ctx))
+	s.Len(s.stockClient.committed, 1)
+}
+
+func (s *BasketServiceTestSuite) TestGivenCheckoutWhileReservationsExpireThenCommittedStockShouldNotBeReleased() {
+	ctx := context.Background()
+	s.givenReservedBasket("b1", "p1")
+
+	// the basket is checked out right after the expirer read its reservations
+	repo := &checkoutOnReadRepository{MemoryRepository: s.repo, onRead: func() {
+		_, err := s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: "b1", UserID: "u1"})
+		s.Require().Nil(err)
+	}}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	err := basket.NewReservationExpirer(&basket.NewReservationExpirerOpts{R: repo, L: logger}).
+		ExpireReservations(ctx)
+	s.Require().Nil(err)
+
+	reservations, err := s.repo.GetReservationsByBasketID(ctx, "b1")
+	s.Require().Nil(err)
+	s.Require().Len(reservations, 1)
+	s.Equal(basket.ReservationStatusCommitted, reservations[0].Status)
+
+	s.Require().Nil(s.newRelay().Relay(ctx))
+	s.Len(s.stockClient.committed, 1)
+	s.Empty(s.stockClient.released)
+}
+
+func (s *BasketServiceTestSuite) TestGivenBasketCheckedOutThenItsEventsShouldBePublishedByTheRelay() {
+	ctx := context.Background()
+
+	created, err := s.service.CreateBasket(ctx, basket.CreateBasketRequest{UserID: "u1"})
+	s.Require().Nil(err)
+	s.Empty(s.publisher.messages)
+
+	_, err = s.service.AddProductToBasket(ctx, basket.AddProductToBasketRequest{
+		BasketID: created.ID, UserID: "u1", ProductID: "p1", Quantity: 2,
+	})
+	s.Require().Nil(err)
+	s.Require().Nil(s.newRelay().Relay(ctx))
+
+	_, err = s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: created.ID, UserID: "u1"})
+	s.Require().Nil(err)
+	s.Require().Nil(s.newRelay().Relay(ctx))
+
+	published := s.publishedEvents()
+	s.Require().Len(published, 3)
+
+	basketCreated := published[0].(*events.BasketCreated)
+	s.Equal(created.ID, basketCreated.BasketID)
+	s.Equal("u1", basketCreated.UserID)
+	s.False(basketCreated.OccurredAt.IsZero())
+
+	itemAdded := published[1].(*events.ItemAdded)
+	s.Equal(created.ID, itemAdded.BasketID)
+	s.Equal("p1", itemAdded.ProductID)
+	s.Equal(2, itemAdded.Quantity)
+	s.Equal(10.0, itemAdded.Price)
+	s.False(itemAdded.OccurredAt.IsZero())
+
+	checkedOut := published[2].(*events.CheckedOut)
+	s.Equal(created.ID, checkedOut.BasketID)
+	s.Equal([]events.Item{{ProductID: "p1", Quantity: 2}}, checkedOut.Items)
+	s.False(checkedOut.CheckedOutAt.IsZero())
+}
+
+func (s *BasketServiceTestSuite) TestGivenCheckoutFailingThenCheckedOutShouldNotBePublished() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+	_, err := s.service.AddProductToBasket(ctx, basket.AddProductToBasketRequest{
+		BasketID: "b1", UserID: "u1", ProductID: "p1", Quantity: 1,
+	})
+	s.Require().Nil(err)
+
+	// the reservation is not delivered yet
+	_, err = s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: "b1", UserID: "u1"})
+	s.Require().Equal(basket.ReservationsPending(), err)
+
+	s.Require().Nil(s.newRelay().Relay(ctx))
+
+	published := s.publishedEvents()
+	s.Require().Len(published, 1)
+	s.Equal(events.ItemAddedType, published[0].EventType())
+}
+
+func (s *BasketServiceTestSuite) TestGivenBasketActionsThenBusinessCountersShouldCountThem() {
+	ctx := context.Background()
+	before := map[string]float64{}
+	for _, series := range []string{basketsCreatedSeries, itemsAddedSeries, outOfStockSeries, checkoutsSeries} {
+		before[series] = s.metric(series)
+	}
+
+	created, err := s.service.CreateBasket(ctx, basket.CreateBasketRequest{UserID: "u1"})
+	s.Require().Nil(err)
+
+	_, err = s.service.AddBulkProductToBasket(ctx, basket.AddBulkProductToBasketRequest{
+		BasketID: created.ID, UserID: "u1",
+		Products: []basket.BulkProduct{{ID: "p1", Quantity: 1}, {ID: "p2", Quantity: 1}},
+	})
+	s.Require().Nil(err)
+
+	s.stockClient.unavailable = true
+	_, err = s.service.AddProductToBasket(ctx, basket.AddProductToBasketRequest{
+		BasketID: created.ID, UserID: "u1", ProductID: "p3", Quantity: 1,
+	})
+	s.Require().Equal(basket.ProductNotHasEnoughStock(), err)
+
+	s.Require().Nil(s.newRelay().Relay(ctx))
+	_, err = s.service.Checkout(ctx, basket.CheckoutRequest{BasketID: created.ID, UserID: "u1"})
+	s.Require().Nil(err)
+
+	s.Equal(before[basketsCreatedSeries]+1, s.metric(basketsCreatedSeries))
+	s.Equal(before[itemsAddedSeries]+2, s.metric(itemsAddedSeries))
+	s.Equal(before[outOfStockSeries]+1, s.metric(outOfStockSeries))
+	s.Equal(before[checkoutsSeries]+1, s.metric(checkoutsSeries))
+}
+
+// checkoutOnReadRepository runs onRead after the reservations are read by
+// status, the way a checkout interleaves with the expirer.
+type checkoutOnReadRepository struct {
+	persistencetest.MemoryRepository
+	onRead func()
+}
+
+func (r *checkoutOnReadRepository) GetReservationsByStatus(
+	ctx context.Context, status basket.ReservationStatus, unchangedFor time.Duration) ([]basket.Reservation, error) {
+	reservations, err := r.MemoryRepository.GetReservationsByStatus(ctx, status, unchangedFor)
+	r.onRead()
+
+	return reservations, err
+}
+
+// relayOnTxRepository runs onTx once, before the first transaction is begun.
+type relayOnTxRepository struct {
+	persistencetest.MemoryRepository
+	onTx func()
+}
+
+func (r *relayOnTxRepository) RunInTx(ctx context.Context, fn func(repo basket.Repository) error) error {
+	if onTx := r.onTx; onTx != nil {
+		r.onTx = nil
+		onTx()
+	}
+
+	return r.MemoryRepository.RunInTx(ctx, fn)
+}
+
+func (s *BasketServiceTestSuite) newRelay() basket.OutboxRelay {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	return basket.NewOutboxRelay(&basket.NewOutboxRelayOpts{
+		R: s.repo, L: logger, SC: s.stockClient, EP: s.publisher,
+	})
+}
+
+// publishedEvents decodes the messages the relay published so far.
+func (s *BasketServiceTestSuite) publishedEvents() []events.Event {
+	published := make([]events.Event, len(s.publisher.messages))
+	for i, message := range s.publisher.messages {
+		s.Equal("application/json", message.Metadata[events.MetadataContentType])
+
+		event, err := events.Decode(events.Type(message.Metadata[events.MetadataEventType]), message.Content)
+		s.Require().Nil(err)
+
+		published[i] = event
+	}
+
+	return published
+}
+
+// givenReservedBasket adds the products to the basket through the service
+// and delivers their reservations.
+func (s *BasketServiceTestSuite) givenReservedBasket(basketID string, productIDs ...string) {
+	ctx := context.Background()
+	s.givenBasket(basketID)
+
+	for _, productID := range productIDs {
+		_, err := s.service.AddProductToBasket(ctx, basket.AddProductToBasketRequest{
+			BasketID: basketID, UserID: "u1", ProductID: productID, Quantity: 1,
+		})
+		s.Require().Nil(err)
+	}
+
+	s.Require().Nil(s.newRelay().Relay(ctx))
+}
+
+// givenBasket stores a basket of user u1 with the given lines.
+func (s *BasketServiceTestSuite) givenBasket(basketID string, products ...basket.Product) {
+	ctx := context.Background()
+
+	_, err := s.repo.CreateBasket(ctx, &basket.Basket{ID: basketID, UserID: "u1"})
+	s.Require().Nil(err)
+
+	for _, prod := range products {
+		prod.BasketID = basketID
+		_, err = s.repo.AddProductToBasket(ctx, &prod)
+		s.Require().Nil(err)
+	}
+}
+
+// metric returns the value of series as the metrics endpoint exposes it, or
+// 0 when it is not exposed yet.
+func (s *BasketServiceTestSuite) metric(series string) float64 {
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, series+" "); ok {
+			v, err := strconv.ParseFloat(value, 64)
+			s.Require().Nil(err)
+			return v
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,82 @@
+package basket
+
+import (
+	"context"
+
+	"github.com/pact-cdc-example/basket-service/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/pact-cdc-example/basket-service/app/basket")
+
+// tracingService wraps every call of the service in a span, the spans of
+// the repository and the clients it calls become its children.
+type tracingService struct {
+	next Service
+}
+
+type NewTracingServiceOpts struct {
+	S Service
+}
+
+func NewTracingService(opts *NewTracingServiceOpts) Service {
+	return &tracingService{next: opts.S}
+}
+
+func (ts *tracingService) CreateBasket(
+	ctx context.Context, req CreateBasketRequest) (*GetBasketResponse, error) {
+	ctx, span := ts.start(ctx, "CreateBasket", "")
+	resp, err := ts.next.CreateBasket(ctx, req)
+	tracing.End(span, err)
+
+	return resp, err
+}
+
+func (ts *tracingService) AddProductToBasket(
+	ctx context.Context, req AddProductToBasketRequest) (*GetBasketResponse, error) {
+	ctx, span := ts.start(ctx, "AddProductToBasket", req.BasketID,
+		attribute.String("product.id", req.ProductID), attribute.Int("product.quantity", req.Quantity))
+	resp, err := ts.next.AddProductToBasket(ctx, req)
+	tracing.End(span, err)
+
+	return resp, err
+}
+
+func (ts *tracingService) GetBasketByID(
+	ctx context.Context, basketID string) (*GetBasketResponse, error) {
+	ctx, span := ts.start(ctx, "GetBasketByID", basketID)
+	resp, err := ts.next.GetBasketByID(ctx, basketID)
+	tracing.End(span, err)
+
+	return resp, err
+}
+
+func (ts *tracingService) AddBulkProductToBasket(
+	ctx context.Context, req AddBulkProductToBasketRequest) (*GetBasketResponse, error) {
+	ctx, span := ts.start(ctx, "AddBulkProductToBasket", req.BasketID,
+		attribute.Int("product.count", len(req.Products)))
+	resp, err := ts.next.AddBulkProductToBasket(ctx, req)
+	tracing.End(span, err)
+
+	return resp, err
+}
+
+func (ts *tracingService) Checkout(
+	ctx context.Context, req CheckoutRequest) (*GetBasketResponse, error) {
+	ctx, span := ts.start(ctx, "Checkout", req.BasketID)
+	resp, err := ts.next.Checkout(ctx, req)
+	tracing.End(span, err)
+
+	return resp, err
+}
+
+func (ts *tracingService) start(
+	ctx context.Context, method string, basketID string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if basketID != "" {
+		attrs = append(attrs, attribute.String("basket.id", basketID))
+	}
+
+	return tracer.Start(ctx, "basket.Service/"+method, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,88 @@
+package basket_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingService answers every call with err and keeps the span context
+// it was called with.
+type recordingService struct {
+	basket.Service
+	err         error
+	spanContext trace.SpanContext
+}
+
+func (rs *recordingService) GetBasketByID(ctx context.Context, basketID string) (*basket.GetBasketResponse, error) {
+	rs.spanContext = trace.SpanContextFromContext(ctx)
+	if rs.err != nil {
+		return nil, rs.err
+	}
+
+	return &basket.GetBasketResponse{ID: basketID}, nil
+}
+
+func (rs *recordingService) Checkout(ctx context.Context, req basket.CheckoutRequest) (*basket.GetBasketResponse, error) {
+	rs.spanContext = trace.SpanContextFromContext(ctx)
+	return nil, rs.err
+}
+
+type TracingServiceTestSuite struct {
+	suite.Suite
+	exporter *tracetest.InMemoryExporter
+	next     *recordingService
+	service  basket.Service
+}
+
+func TestTracingServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingServiceTestSuite))
+}
+
+func (s *TracingServiceTestSuite) SetupSuite() {
+	s.exporter = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(s.exporter)))
+}
+
+func (s *TracingServiceTestSuite) SetupTest() {
+	s.exporter.Reset()
+	s.next = &recordingService{}
+	s.service = basket.NewTracingService(&basket.NewTracingServiceOpts{S: s.next})
+}
+
+func (s *TracingServiceTestSuite) TestGivenCallThenItShouldRunInItsSpan() {
+	resp, err := s.service.GetBasketByID(context.Background(), "b1")
+
+	s.Nil(err)
+	s.Equal("b1", resp.ID)
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Equal("basket.Service/GetBasketByID", spans[0].Name)
+	s.Equal(spans[0].SpanContext.SpanID(), s.next.spanContext.SpanID())
+	s.Contains(spans[0].Attributes, attribute.String("basket.id", "b1"))
+	s.Equal(codes.Unset, spans[0].Status.Code)
+}
+
+func (s *TracingServiceTestSuite) TestGivenFailingCallThenTheErrorShouldBeRecorded() {
+	s.next.err = basket.ReservationsPending()
+
+	_, err := s.service.Checkout(context.Background(), basket.CheckoutRequest{BasketID: "b1", UserID: "u1"})
+
+	s.Equal(basket.ReservationsPending(), err)
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Equal("basket.Service/Checkout", spans[0].Name)
+	s.Equal(codes.Error, spans[0].Status.Code)
+	s.Require().Len(spans[0].Events, 1)
+	s.Equal("exception", spans[0].Events[0].Name)
+}
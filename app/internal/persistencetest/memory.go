@@ -0,0 +1,401 @@
+// Package persistencetest provides a basket repository for tests which run
+// the service without a database.
+package persistencetest
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+)
+
+// MemoryRepository keeps the baskets in memory. It is meant for tests which
+// run the service without a database, such as the provider verification.
+type MemoryRepository interface {
+	basket.Repository
+	// Reset removes everything the repository holds.
+	Reset()
+}
+
+type memoryRepository struct {
+	store *memoryStore
+	// tx is set on the repository a transaction runs with, it records how to
+	// undo the writes of the transaction.
+	tx *memoryTx
+}
+
+type memoryStore struct {
+	mu   sync.Mutex
+	txMu sync.Mutex
+	data memoryData
+}
+
+type memoryData struct {
+	baskets      map[string]basket.Basket
+	reservations map[string]basket.Reservation
+	outbox       map[string]memoryOutboxMessage
+}
+
+type memoryOutboxMessage struct {
+	message       basket.OutboxMessage
+	nextAttemptAt time.Time
+}
+
+// memoryTx is an undo log, a rollback restores only the entries the
+// transaction wrote and keeps the writes made beside it.
+type memoryTx struct {
+	undo []func()
+}
+
+func NewMemoryRepository() MemoryRepository {
+	return &memoryRepository{store: &memoryStore{data: newMemoryData()}}
+}
+
+func newMemoryData() memoryData {
+	return memoryData{
+		baskets:      make(map[string]basket.Basket),
+		reservations: make(map[string]basket.Reservation),
+		outbox:       make(map[string]memoryOutboxMessage),
+	}
+}
+
+// remember records how to restore the entry id of m when the transaction of
+// the repository is rolled back. It must be called with the store locked and
+// before the entry is written.
+func remember[T any](tx *memoryTx, m map[string]T, id string) {
+	if tx == nil {
+		return
+	}
+
+	prev, ok := m[id]
+	tx.undo = append(tx.undo, func() {
+		if ok {
+			m[id] = prev
+		} else {
+			delete(m, id)
+		}
+	})
+}
+
+func (mr *memoryRepository) Reset() {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	mr.store.data = newMemoryData()
+}
+
+// RunInTx runs the transactions one at a time and undoes the writes of fn
+// when it fails. A transaction run within another one joins it.
+func (mr *memoryRepository) RunInTx(
+	ctx context.Context, fn func(repo basket.Repository) error) error {
+	if mr.tx != nil {
+		return fn(mr)
+	}
+
+	mr.store.txMu.Lock()
+	defer mr.store.txMu.Unlock()
+
+	tx := &memoryRepository{store: mr.store, tx: &memoryTx{}}
+	if err := fn(tx); err != nil {
+		mr.store.mu.Lock()
+		for i := len(tx.tx.undo) - 1; i >= 0; i-- {
+			tx.tx.undo[i]()
+		}
+		mr.store.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (mr *memoryRepository) CreateBasket(
+	ctx context.Context, bask *basket.Basket) (*basket.Basket, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	remember(mr.tx, mr.store.data.baskets, bask.ID)
+	mr.store.data.baskets[bask.ID] = basket.Basket{
+		ID:        bask.ID,
+		UserID:    bask.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return mr.getBasketByID(bask.ID)
+}
+
+func (mr *memoryRepository) GetBasketByID(
+	ctx context.Context, basketID string) (*basket.Basket, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	return mr.getBasketByID(basketID)
+}
+
+func (mr *memoryRepository) getBasketByID(basketID string) (*basket.Basket, error) {
+	bask, ok := mr.store.data.baskets[basketID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	bask.Products = append([]basket.Product(nil), bask.Products...)
+
+	return &bask, nil
+}
+
+func (mr *memoryRepository) AddProductToBasket(
+	ctx context.Context, product *basket.Product) (*basket.Basket, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	bask, ok := mr.store.data.baskets[product.BasketID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	now := time.Now().UTC()
+	p := *product
+	p.CreatedAt, p.UpdatedAt = now, now
+	bask.Products = append(append([]basket.Product(nil), bask.Products...), p)
+
+	remember(mr.tx, mr.store.data.baskets, bask.ID)
+	mr.store.data.baskets[bask.ID] = bask
+
+	return mr.getBasketByID(bask.ID)
+}
+
+func (mr *memoryRepository) RemoveProductFromBasket(
+	ctx context.Context, basketID string, productID string) (*basket.Basket, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	bask, ok := mr.store.data.baskets[basketID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	products := make([]basket.Product, 0, len(bask.Products))
+	for _, p := range bask.Products {
+		if p.ID != productID {
+			products = append(products, p)
+		}
+	}
+	bask.Products = products
+
+	remember(mr.tx, mr.store.data.baskets, basketID)
+	mr.store.data.baskets[basketID] = bask
+
+	return mr.getBasketByID(basketID)
+}
+
+func (mr *memoryRepository) CheckoutBasket(
+	ctx context.Context, basketID string) (*basket.Basket, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	bask, ok := mr.store.data.baskets[basketID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	if bask.CheckedOutAt != nil {
+		return nil, basket.BasketCheckedOut()
+	}
+
+	now := time.Now().UTC()
+	bask.CheckedOutAt = &now
+	bask.UpdatedAt = now
+
+	remember(mr.tx, mr.store.data.baskets, basketID)
+	mr.store.data.baskets[basketID] = bask
+
+	return mr.getBasketByID(basketID)
+}
+
+func (mr *memoryRepository) CreateReservation(
+	ctx context.Context, reservation *basket.Reservation) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	r := *reservation
+	r.CreatedAt, r.UpdatedAt = now, now
+
+	remember(mr.tx, mr.store.data.reservations, r.ID)
+	mr.store.data.reservations[r.ID] = r
+
+	return nil
+}
+
+func (mr *memoryRepository) UpdateReservationStatus(
+	ctx context.Context, reservationID string, from basket.ReservationStatus, to basket.ReservationStatus) (bool, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	r, ok := mr.store.data.reservations[reservationID]
+	if !ok || r.Status != from {
+		return false, nil
+	}
+
+	r.Status, r.UpdatedAt = to, time.Now().UTC()
+
+	remember(mr.tx, mr.store.data.reservations, reservationID)
+	mr.store.data.reservations[reservationID] = r
+
+	return true, nil
+}
+
+func (mr *memoryRepository) ConfirmReservation(
+	ctx context.Context, reservationID string, stockID string) (bool, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	r, ok := mr.store.data.reservations[reservationID]
+	if !ok || r.Status != basket.ReservationStatusPending {
+		return false, nil
+	}
+
+	r.Status, r.StockID, r.UpdatedAt = basket.ReservationStatusReserved, stockID, time.Now().UTC()
+
+	remember(mr.tx, mr.store.data.reservations, reservationID)
+	mr.store.data.reservations[reservationID] = r
+
+	return true, nil
+}
+
+func (mr *memoryRepository) GetReservationByID(
+	ctx context.Context, reservationID string) (*basket.Reservation, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	r, ok := mr.store.data.reservations[reservationID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &r, nil
+}
+
+func (mr *memoryRepository) GetReservationsByBasketID(
+	ctx context.Context, basketID string) ([]basket.Reservation, error) {
+	return mr.filterReservations(func(r basket.Reservation) bool {
+		return r.BasketID == basketID
+	}), nil
+}
+
+// LockReservationsByBasketID needs no lock of its own, the transactions of
+// the memory repository already run one at a time.
+func (mr *memoryRepository) LockReservationsByBasketID(
+	ctx context.Context, basketID string) ([]basket.Reservation, error) {
+	return mr.GetReservationsByBasketID(ctx, basketID)
+}
+
+func (mr *memoryRepository) GetReservationsByStatus(
+	ctx context.Context, status basket.ReservationStatus, unchangedFor time.Duration) ([]basket.Reservation, error) {
+	before := time.Now().UTC().Add(-unchangedFor)
+
+	return mr.filterReservations(func(r basket.Reservation) bool {
+		return r.Status == status && !r.UpdatedAt.After(before)
+	}), nil
+}
+
+func (mr *memoryRepository) filterReservations(match func(r basket.Reservation) bool) []basket.Reservation {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	var reservations []basket.Reservation
+	for _, r := range mr.store.data.reservations {
+		if match(r) {
+			reservations = append(reservations, r)
+		}
+	}
+
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].CreatedAt.Before(reservations[j].CreatedAt)
+	})
+
+	return reservations
+}
+
+func (mr *memoryRepository) CreateOutboxMessage(
+	ctx context.Context, message *basket.OutboxMessage) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	m := *message
+	m.Status, m.CreatedAt = basket.OutboxStatusPending, now
+
+	remember(mr.tx, mr.store.data.outbox, m.ID)
+	mr.store.data.outbox[m.ID] = memoryOutboxMessage{message: m, nextAttemptAt: now}
+
+	return nil
+}
+
+func (mr *memoryRepository) ClaimOutboxMessages(
+	ctx context.Context, limit int, lease time.Duration) ([]basket.OutboxMessage, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	var due []basket.OutboxMessage
+	for _, m := range mr.store.data.outbox {
+		if m.message.Status == basket.OutboxStatusPending && !m.nextAttemptAt.After(now) {
+			due = append(due, m.message)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for _, m := range due {
+		remember(mr.tx, mr.store.data.outbox, m.ID)
+		mr.store.data.outbox[m.ID] = memoryOutboxMessage{message: m, nextAttemptAt: now.Add(lease)}
+	}
+
+	return due, nil
+}
+
+func (mr *memoryRepository) MarkOutboxMessageDelivered(
+	ctx context.Context, messageID string) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	if m, ok := mr.store.data.outbox[messageID]; ok {
+		m.message.Status = basket.OutboxStatusDelivered
+		m.message.Attempts++
+
+		remember(mr.tx, mr.store.data.outbox, messageID)
+		mr.store.data.outbox[messageID] = m
+	}
+
+	return nil
+}
+
+func (mr *memoryRepository) MarkOutboxMessageFailed(
+	ctx context.Context, message *basket.OutboxMessage, retryIn time.Duration) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	if m, ok := mr.store.data.outbox[message.ID]; ok {
+		m.message.Status = message.Status
+		m.message.Attempts = message.Attempts
+		m.message.LastError = message.LastError
+		m.nextAttemptAt = time.Now().UTC().Add(retryIn)
+
+		remember(mr.tx, mr.store.data.outbox, message.ID)
+		mr.store.data.outbox[message.ID] = m
+	}
+
+	return nil
+}
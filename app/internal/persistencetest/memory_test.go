@@ -0,0 +1,120 @@
+package persistencetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/app/internal/persistencetest"
+	"github.com/stretchr/testify/suite"
+)
+
+var errRollback = errors.New("rollback")
+
+type MemoryRepositoryTestSuite struct {
+	suite.Suite
+	repo persistencetest.MemoryRepository
+}
+
+func TestMemoryRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryRepositoryTestSuite))
+}
+
+func (s *MemoryRepositoryTestSuite) SetupTest() {
+	s.repo = persistencetest.NewMemoryRepository()
+}
+
+func (s *MemoryRepositoryTestSuite) TestGivenFailingTransactionThenItsWritesShouldBeUndone() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+
+	err := s.repo.RunInTx(ctx, func(repo basket.Repository) error {
+		if _, err := repo.AddProductToBasket(ctx, &basket.Product{ID: "p1", Quantity: 1, BasketID: "b1"}); err != nil {
+			return err
+		}
+		if err := repo.CreateReservation(ctx, &basket.Reservation{ID: "r1", BasketID: "b1"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+
+	s.ErrorIs(err, errRollback)
+
+	bask, err := s.repo.GetBasketByID(ctx, "b1")
+	s.Require().Nil(err)
+	s.Empty(bask.Products)
+
+	_, err = s.repo.GetReservationByID(ctx, "r1")
+	s.NotNil(err)
+}
+
+func (s *MemoryRepositoryTestSuite) TestGivenFailingTransactionThenWritesBesideItShouldBeKept() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+
+	err := s.repo.RunInTx(ctx, func(repo basket.Repository) error {
+		if _, err := repo.AddProductToBasket(ctx, &basket.Product{ID: "p1", Quantity: 1, BasketID: "b1"}); err != nil {
+			return err
+		}
+
+		// written outside of the transaction while it runs
+		s.givenBasket("b2")
+		s.Require().Nil(s.repo.CreateReservation(ctx, &basket.Reservation{ID: "r2", BasketID: "b2"}))
+
+		return errRollback
+	})
+
+	s.ErrorIs(err, errRollback)
+
+	_, err = s.repo.GetBasketByID(ctx, "b2")
+	s.Nil(err)
+	_, err = s.repo.GetReservationByID(ctx, "r2")
+	s.Nil(err)
+}
+
+func (s *MemoryRepositoryTestSuite) TestGivenNestedTransactionThenItShouldJoinTheOuterOne() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.repo.RunInTx(ctx, func(repo basket.Repository) error {
+			return repo.RunInTx(ctx, func(repo basket.Repository) error {
+				_, err := repo.AddProductToBasket(ctx, &basket.Product{ID: "p1", Quantity: 1, BasketID: "b1"})
+				if err != nil {
+					return err
+				}
+				return errRollback
+			})
+		})
+	}()
+
+	select {
+	case err := <-done:
+		s.ErrorIs(err, errRollback)
+	case <-time.After(time.Second):
+		s.FailNow("nested transaction deadlocked")
+	}
+
+	bask, err := s.repo.GetBasketByID(ctx, "b1")
+	s.Require().Nil(err)
+	s.Empty(bask.Products)
+}
+
+func (s *MemoryRepositoryTestSuite) TestGivenCheckedOutBasketThenCheckoutShouldFail() {
+	ctx := context.Background()
+	s.givenBasket("b1")
+
+	_, err := s.repo.CheckoutBasket(ctx, "b1")
+	s.Require().Nil(err)
+
+	_, err = s.repo.CheckoutBasket(ctx, "b1")
+	s.Equal(basket.BasketCheckedOut(), err)
+}
+
+func (s *MemoryRepositoryTestSuite) givenBasket(basketID string) {
+	_, err := s.repo.CreateBasket(context.Background(), &basket.Basket{ID: basketID, UserID: "u1"})
+	s.Require().Nil(err)
+}
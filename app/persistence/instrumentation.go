@@ -0,0 +1,42 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/pact-cdc-example/basket-service/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of the repository operations by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	tracer = otel.Tracer("github.com/pact-cdc-example/basket-service/app/persistence")
+)
+
+// startQuery starts the span of a repository operation, the returned func
+// ends it with the error the operation failed with, if any, and observes the
+// latency of the operation. An operation may run more than one query.
+func startQuery(ctx context.Context, operation string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperation(operation)),
+	)
+
+	return ctx, func(err error) {
+		tracing.End(span, err)
+		queryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	}
+}
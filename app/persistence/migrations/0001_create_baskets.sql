@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS baskets (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS basket_products (
+    basket_id  TEXT      NOT NULL REFERENCES baskets (id),
+    product_id TEXT      NOT NULL,
+    quantity   INT       NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
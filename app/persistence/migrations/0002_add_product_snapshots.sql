@@ -0,0 +1,6 @@
+ALTER TABLE basket_products
+    ADD COLUMN IF NOT EXISTS product_name      TEXT,
+    ADD COLUMN IF NOT EXISTS product_code      TEXT,
+    ADD COLUMN IF NOT EXISTS product_price     DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS product_image_url TEXT,
+    ADD COLUMN IF NOT EXISTS product_type      TEXT;
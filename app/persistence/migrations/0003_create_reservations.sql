@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS reservations (
+    id         TEXT PRIMARY KEY,
+    basket_id  TEXT      NOT NULL REFERENCES baskets (id),
+    product_id TEXT      NOT NULL,
+    quantity   INT       NOT NULL,
+    stock_id   TEXT,
+    status     TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS reservations_basket_id_idx ON reservations (basket_id);
+CREATE INDEX IF NOT EXISTS reservations_status_idx ON reservations (status, updated_at);
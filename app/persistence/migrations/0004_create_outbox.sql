@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id              TEXT PRIMARY KEY,
+    basket_id       TEXT      NOT NULL,
+    command         TEXT      NOT NULL,
+    payload         JSONB     NOT NULL,
+    status          TEXT      NOT NULL,
+    attempts        INT       NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS outbox_status_next_attempt_at_idx ON outbox (status, next_attempt_at);
@@ -0,0 +1,2 @@
+ALTER TABLE baskets
+    ADD COLUMN IF NOT EXISTS checked_out_at TIMESTAMP;
@@ -0,0 +1,121 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+)
+
+func (pr *postgresRepository) CreateOutboxMessage(
+	ctx context.Context, message *basket.OutboxMessage) (err error) {
+	ctx, end := startQuery(ctx, "create_outbox_message")
+	defer func() { end(err) }()
+
+	_, err = pr.q.ExecContext(ctx,
+		`INSERT INTO outbox (id, basket_id, command, payload, status)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		message.ID, message.BasketID, message.Command, []byte(message.Payload), basket.OutboxStatusPending,
+	)
+
+	if err != nil {
+		pr.logger.Errorf("could not create outbox message: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ClaimOutboxMessages returns the pending messages which are due and hides
+// them from other relays for the lease duration, a message whose delivery
+// outcome is never recorded becomes due again once the lease is over.
+func (pr *postgresRepository) ClaimOutboxMessages(
+	ctx context.Context, limit int, lease time.Duration) (_ []basket.OutboxMessage, err error) {
+	ctx, end := startQuery(ctx, "claim_outbox_messages")
+	defer func() { end(err) }()
+
+	rows, err := pr.q.QueryContext(ctx,
+		`UPDATE outbox SET next_attempt_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE status = $1 AND next_attempt_at <= NOW()
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, basket_id, command, payload, status, attempts, last_error, created_at`,
+		basket.OutboxStatusPending, limit, lease.Milliseconds(),
+	)
+
+	if err != nil {
+		pr.logger.Errorf("could not claim outbox messages: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []basket.OutboxMessage
+	for rows.Next() {
+		var message basket.OutboxMessage
+		var payload []byte
+		var lastError sql.NullString
+		if err := rows.Scan(
+			&message.ID,
+			&message.BasketID,
+			&message.Command,
+			&payload,
+			&message.Status,
+			&message.Attempts,
+			&lastError,
+			&message.CreatedAt,
+		); err != nil {
+			pr.logger.Errorf("could not scan outbox message: %v", err)
+			return nil, err
+		}
+		message.Payload = payload
+		message.LastError = lastError.String
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+func (pr *postgresRepository) MarkOutboxMessageDelivered(
+	ctx context.Context, messageID string) (err error) {
+	ctx, end := startQuery(ctx, "mark_outbox_message_delivered")
+	defer func() { end(err) }()
+
+	_, err = pr.q.ExecContext(ctx,
+		`UPDATE outbox SET status = $2, attempts = attempts + 1, updated_at = NOW() WHERE id = $1`,
+		messageID, basket.OutboxStatusDelivered,
+	)
+
+	if err != nil {
+		pr.logger.Errorf("could not mark outbox message delivered: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// MarkOutboxMessageFailed records a failed delivery with the status, attempts
+// and last error set on message, a pending message is retried after retryIn.
+func (pr *postgresRepository) MarkOutboxMessageFailed(
+	ctx context.Context, message *basket.OutboxMessage, retryIn time.Duration) (err error) {
+	ctx, end := startQuery(ctx, "mark_outbox_message_failed")
+	defer func() { end(err) }()
+
+	_, err = pr.q.ExecContext(ctx,
+		`UPDATE outbox SET status = $2, attempts = $3, last_error = $4,
+		next_attempt_at = NOW() + $5 * INTERVAL '1 millisecond', updated_at = NOW()
+		WHERE id = $1`,
+		message.ID, message.Status, message.Attempts, message.LastError, retryIn.Milliseconds(),
+	)
+
+	if err != nil {
+		pr.logger.Errorf("could not mark outbox message failed: %v", err)
+		return err
+	}
+
+	return nil
+}
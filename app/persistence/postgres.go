@@ -3,6 +3,7 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/pact-cdc-example/basket-service/app/basket"
 
@@ -16,10 +17,47 @@ type PostgresRepository interface {
 		ctx context.Context, product *basket.Product) (*basket.Basket, error)
 	GetBasketByID(
 		ctx context.Context, basketID string) (*basket.Basket, error)
+	RemoveProductFromBasket(
+		ctx context.Context, basketID string, productID string) (*basket.Basket, error)
+	CheckoutBasket(
+		ctx context.Context, basketID string) (*basket.Basket, error)
+	CreateReservation(
+		ctx context.Context, reservation *basket.Reservation) error
+	UpdateReservationStatus(
+		ctx context.Context, reservationID string, from basket.ReservationStatus, to basket.ReservationStatus) (bool, error)
+	GetReservationsByBasketID(
+		ctx context.Context, basketID string) ([]basket.Reservation, error)
+	LockReservationsByBasketID(
+		ctx context.Context, basketID string) ([]basket.Reservation, error)
+	GetReservationsByStatus(
+		ctx context.Context, status basket.ReservationStatus, unchangedFor time.Duration) ([]basket.Reservation, error)
+	GetReservationByID(
+		ctx context.Context, reservationID string) (*basket.Reservation, error)
+	ConfirmReservation(
+		ctx context.Context, reservationID string, stockID string) (bool, error)
+	CreateOutboxMessage(
+		ctx context.Context, message *basket.OutboxMessage) error
+	ClaimOutboxMessages(
+		ctx context.Context, limit int, lease time.Duration) ([]basket.OutboxMessage, error)
+	MarkOutboxMessageDelivered(
+		ctx context.Context, messageID string) error
+	MarkOutboxMessageFailed(
+		ctx context.Context, message *basket.OutboxMessage, retryIn time.Duration) error
+	RunInTx(
+		ctx context.Context, fn func(repo basket.Repository) error) error
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx, so the same queries run
+// in and out of a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 type postgresRepository struct {
 	db     *sql.DB
+	q      querier
 	logger *logrus.Logger
 }
 
@@ -31,13 +69,39 @@ type NewPostgresRepositoryOpts struct {
 func NewPostgresRepository(opts *NewPostgresRepositoryOpts) PostgresRepository {
 	return &postgresRepository{
 		db:     opts.DB,
+		q:      opts.DB,
 		logger: opts.L,
 	}
 }
 
+// RunInTx runs fn with a repository bound to a single transaction, which is
+// committed when fn succeeds. Nested calls join the outer transaction.
+func (pr *postgresRepository) RunInTx(
+	ctx context.Context, fn func(repo basket.Repository) error) error {
+	if _, ok := pr.q.(*sql.Tx); ok {
+		return fn(pr)
+	}
+
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		pr.logger.Errorf("could not begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = fn(&postgresRepository{db: pr.db, q: tx, logger: pr.logger}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (pr *postgresRepository) CreateBasket(
-	ctx context.Context, bask *basket.Basket) (*basket.Basket, error) {
-	err := pr.db.QueryRowContext(ctx,
+	ctx context.Context, bask *basket.Basket) (_ *basket.Basket, err error) {
+	ctx, end := startQuery(ctx, "create_basket")
+	defer func() { end(err) }()
+
+	err = pr.q.QueryRowContext(ctx,
 		`INSERT INTO baskets (id, user_id)
 		 VALUES ($1, $2) RETURNING created_at`,
 		bask.ID, bask.UserID,
@@ -54,15 +118,17 @@ func (pr *postgresRepository) CreateBasket(
 func (pr *postgresRepository) getBasketByID(
 	ctx context.Context, basketID string) (*basket.Basket, error) {
 
-	row := pr.db.QueryRowContext(ctx,
-		`SELECT id, user_id, created_at, updated_at 
+	row := pr.q.QueryRowContext(ctx,
+		`SELECT id, user_id, checked_out_at, created_at, updated_at
 		FROM baskets WHERE ID = $1`, basketID,
 	)
 
 	var bask basket.Basket
+	var checkedOutAt sql.NullTime
 	if err := row.Scan(
 		&bask.ID,
 		&bask.UserID,
+		&checkedOutAt,
 		&bask.CreatedAt,
 		&bask.UpdatedAt,
 	); err != nil {
@@ -70,25 +136,38 @@ func (pr *postgresRepository) getBasketByID(
 		return nil, err
 	}
 
-	rows, err := pr.db.QueryContext(ctx,
-		`SELECT product_id, quantity FROM basket_products
-		WHERE basket_id = $1`, basketID)
+	if checkedOutAt.Valid {
+		bask.CheckedOutAt = &checkedOutAt.Time
+	}
+
+	rows, err := pr.q.QueryContext(ctx,
+		`SELECT product_id, quantity, product_name, product_code,
+		product_price, product_image_url, product_type
+		FROM basket_products WHERE basket_id = $1`, basketID)
 
 	if err != nil {
 		pr.logger.Errorf("could not get basket products: %v", err)
 		return nil, err
 	}
+	defer rows.Close()
 
 	var products []basket.Product
 	for rows.Next() {
 		var product basket.Product
+		var snapshot productSnapshotColumns
 		if err := rows.Scan(
 			&product.ID,
 			&product.Quantity,
+			&snapshot.name,
+			&snapshot.code,
+			&snapshot.price,
+			&snapshot.imageURL,
+			&snapshot.productType,
 		); err != nil {
 			pr.logger.Errorf("could not scan basket product: %v", err)
 			return nil, err
 		}
+		product.Snapshot = snapshot.toSnapshot()
 		products = append(products, product)
 	}
 
@@ -98,11 +177,18 @@ func (pr *postgresRepository) getBasketByID(
 }
 
 func (pr *postgresRepository) AddProductToBasket(
-	ctx context.Context, product *basket.Product) (*basket.Basket, error) {
-	err := pr.db.QueryRowContext(ctx,
-		`INSERT INTO basket_products (product_id, quantity, basket_id)
-		 VALUES ($1, $2, $3)`,
-		product.ID, product.Quantity, product.BasketID,
+	ctx context.Context, product *basket.Product) (_ *basket.Basket, err error) {
+	ctx, end := startQuery(ctx, "add_product_to_basket")
+	defer func() { end(err) }()
+
+	snapshot := newProductSnapshotColumns(product.Snapshot)
+
+	err = pr.q.QueryRowContext(ctx,
+		`INSERT INTO basket_products (product_id, quantity, basket_id, product_name,
+		 product_code, product_price, product_image_url, product_type)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		product.ID, product.Quantity, product.BasketID, snapshot.name,
+		snapshot.code, snapshot.price, snapshot.imageURL, snapshot.productType,
 	).Err()
 
 	if err != nil {
@@ -113,7 +199,57 @@ func (pr *postgresRepository) AddProductToBasket(
 	return pr.getBasketByID(ctx, product.BasketID)
 }
 
+func (pr *postgresRepository) RemoveProductFromBasket(
+	ctx context.Context, basketID string, productID string) (_ *basket.Basket, err error) {
+	ctx, end := startQuery(ctx, "remove_product_from_basket")
+	defer func() { end(err) }()
+
+	_, err = pr.q.ExecContext(ctx,
+		`DELETE FROM basket_products WHERE basket_id = $1 AND product_id = $2`,
+		basketID, productID,
+	)
+
+	if err != nil {
+		pr.logger.Errorf("could not remove product from basket: %v", err)
+		return nil, err
+	}
+
+	return pr.getBasketByID(ctx, basketID)
+}
+
+func (pr *postgresRepository) CheckoutBasket(
+	ctx context.Context, basketID string) (_ *basket.Basket, err error) {
+	ctx, end := startQuery(ctx, "checkout_basket")
+	defer func() { end(err) }()
+
+	result, err := pr.q.ExecContext(ctx,
+		`UPDATE baskets SET checked_out_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND checked_out_at IS NULL`,
+		basketID,
+	)
+
+	if err != nil {
+		pr.logger.Errorf("could not checkout basket: %v", err)
+		return nil, err
+	}
+
+	checkedOut, err := result.RowsAffected()
+	if err != nil {
+		pr.logger.Errorf("could not checkout basket: %v", err)
+		return nil, err
+	}
+
+	if checkedOut == 0 {
+		return nil, basket.BasketCheckedOut()
+	}
+
+	return pr.getBasketByID(ctx, basketID)
+}
+
 func (pr *postgresRepository) GetBasketByID(
-	ctx context.Context, basketID string) (*basket.Basket, error) {
+	ctx context.Context, basketID string) (_ *basket.Basket, err error) {
+	ctx, end := startQuery(ctx, "get_basket_by_id")
+	defer func() { end(err) }()
+
 	return pr.getBasketByID(ctx, basketID)
 }
@@ -0,0 +1,258 @@
+package persistence_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/app/persistence"
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/pact-cdc-example/basket-service/pkg/postgres"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	selectBasket = `SELECT id, user_id, checked_out_at, created_at, updated_at
+		FROM baskets WHERE ID = $1`
+	selectBasketProducts = `SELECT product_id, quantity, product_name, product_code,
+		product_price, product_image_url, product_type
+		FROM basket_products WHERE basket_id = $1`
+)
+
+var basketProductColumns = []string{
+	"product_id", "quantity", "product_name", "product_code",
+	"product_price", "product_image_url", "product_type",
+}
+
+type PostgresRepositoryTestSuite struct {
+	suite.Suite
+	db       *sql.DB
+	mock     sqlmock.Sqlmock
+	repo     persistence.PostgresRepository
+	exporter *tracetest.InMemoryExporter
+}
+
+func TestPostgresRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresRepositoryTestSuite))
+}
+
+func (s *PostgresRepositoryTestSuite) SetupSuite() {
+	s.exporter = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(s.exporter)))
+}
+
+func (s *PostgresRepositoryTestSuite) SetupTest() {
+	s.exporter.Reset()
+
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	s.Require().Nil(err)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	s.repo = persistence.NewPostgresRepository(&persistence.NewPostgresRepositoryOpts{DB: s.db, L: logger})
+}
+
+func (s *PostgresRepositoryTestSuite) TearDownTest() {
+	s.Nil(s.mock.ExpectationsWereMet())
+	s.mock.ExpectClose()
+	s.Nil(s.db.Close())
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenProductWithSnapshotThenItShouldBeStoredAndReadBack() {
+	snapshot := &basket.ProductSnapshot{Name: "shoe", Code: "S1", Price: 12.5, ImageURL: "img", Type: "footwear"}
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO basket_products")).
+		WithArgs("p1", 2, "b1", "shoe", "S1", 12.5, "img", "footwear").
+		WillReturnRows(sqlmock.NewRows(nil))
+	s.expectBasket("b1", sqlmock.NewRows(basketProductColumns).
+		AddRow("p1", 2, "shoe", "S1", 12.5, "img", "footwear"))
+
+	bask, err := s.repo.AddProductToBasket(context.Background(), &basket.Product{
+		ID: "p1", Quantity: 2, BasketID: "b1", Snapshot: snapshot,
+	})
+
+	s.Require().Nil(err)
+	s.Equal(snapshot, bask.Products[0].Snapshot)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenLegacyLineWithoutSnapshotThenItShouldBeReadWithoutSnapshot() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO basket_products")).
+		WithArgs("p1", 1, "b1", nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows(nil))
+	s.expectBasket("b1", sqlmock.NewRows(basketProductColumns).
+		AddRow("p1", 1, nil, nil, nil, nil, nil))
+
+	bask, err := s.repo.AddProductToBasket(context.Background(), &basket.Product{
+		ID: "p1", Quantity: 1, BasketID: "b1",
+	})
+
+	s.Require().Nil(err)
+	s.Nil(bask.Products[0].Snapshot)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenReservationNoLongerPendingThenItShouldNotBeConfirmed() {
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE reservations SET status = $2, stock_id = $3")).
+		WithArgs("r1", basket.ReservationStatusReserved, "s1", basket.ReservationStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	confirmed, err := s.repo.ConfirmReservation(context.Background(), "r1", "s1")
+
+	s.Nil(err)
+	s.False(confirmed)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenPendingReservationThenItShouldBeConfirmed() {
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE reservations SET status = $2, stock_id = $3")).
+		WithArgs("r1", basket.ReservationStatusReserved, "s1", basket.ReservationStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	confirmed, err := s.repo.ConfirmReservation(context.Background(), "r1", "s1")
+
+	s.Nil(err)
+	s.True(confirmed)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenReservationChangedMeanwhileThenItsStatusShouldNotBeUpdated() {
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE reservations SET status = $2, updated_at = NOW() WHERE id = $1 AND status = $3")).
+		WithArgs("r1", basket.ReservationStatusExpired, basket.ReservationStatusReserved).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	updated, err := s.repo.UpdateReservationStatus(
+		context.Background(), "r1", basket.ReservationStatusReserved, basket.ReservationStatusExpired)
+
+	s.Nil(err)
+	s.False(updated)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenBasketReservationsThenTheyShouldBeLockedForUpdate() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("FROM reservations WHERE basket_id = $1 ORDER BY created_at FOR UPDATE")).
+		WithArgs("b1").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "basket_id", "product_id", "quantity", "stock_id", "status", "created_at", "updated_at",
+		}).AddRow("r1", "b1", "p1", 1, "s1", basket.ReservationStatusReserved, time.Now(), time.Now()))
+
+	reservations, err := s.repo.LockReservationsByBasketID(context.Background(), "b1")
+
+	s.Require().Nil(err)
+	s.Require().Len(reservations, 1)
+	s.Equal("r1", reservations[0].ID)
+	s.Equal("s1", reservations[0].StockID)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenBasketAlreadyCheckedOutThenCheckoutShouldFail() {
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE baskets SET checked_out_at = NOW()")).
+		WithArgs("b1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	bask, err := s.repo.CheckoutBasket(context.Background(), "b1")
+
+	s.Nil(bask)
+	s.Equal(basket.BasketCheckedOut(), err)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenEmbeddedMigrationsThenTheyShouldHaveVersions() {
+	version, err := postgres.LatestVersion(persistence.Migrations())
+
+	s.Nil(err)
+	s.Equal(5, version)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenOperationThenItShouldRunInItsSpan() {
+	s.expectBasket("b1", sqlmock.NewRows(basketProductColumns))
+
+	_, err := s.repo.GetBasketByID(context.Background(), "b1")
+
+	s.Require().Nil(err)
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Equal("postgres get_basket_by_id", spans[0].Name)
+	s.Contains(spans[0].Attributes, attribute.String("db.operation", "get_basket_by_id"))
+	s.Equal(codes.Unset, spans[0].Status.Code)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenFailingOperationThenTheErrorShouldBeRecordedOnItsSpan() {
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO reservations")).
+		WillReturnError(errors.New("connection reset"))
+
+	err := s.repo.CreateReservation(context.Background(), &basket.Reservation{ID: "r1", BasketID: "b1"})
+
+	s.NotNil(err)
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Equal("postgres create_reservation", spans[0].Name)
+	s.Equal(codes.Error, spans[0].Status.Code)
+	s.Equal("connection reset", spans[0].Status.Description)
+	s.Require().Len(spans[0].Events, 1)
+	s.Equal("exception", spans[0].Events[0].Name)
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenOperationThenItsLatencyShouldBeObservedByOperation() {
+	series := `basket_service_db_query_duration_seconds_count{operation="get_basket_by_id"}`
+	before := s.metric(series)
+	s.expectBasket("b1", sqlmock.NewRows(basketProductColumns))
+
+	_, err := s.repo.GetBasketByID(context.Background(), "b1")
+
+	s.Require().Nil(err)
+	s.Equal(before+1, s.metric(series))
+}
+
+func (s *PostgresRepositoryTestSuite) TestGivenFailingOperationThenItsLatencyShouldStillBeObserved() {
+	series := `basket_service_db_query_duration_seconds_count{operation="checkout_basket"}`
+	before := s.metric(series)
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE baskets SET checked_out_at = NOW()")).
+		WithArgs("b1").
+		WillReturnError(errors.New("connection reset"))
+
+	_, err := s.repo.CheckoutBasket(context.Background(), "b1")
+
+	s.NotNil(err)
+	s.Equal(before+1, s.metric(series))
+}
+
+func (s *PostgresRepositoryTestSuite) expectBasket(basketID string, products *sqlmock.Rows) {
+	now := time.Now()
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectBasket)).
+		WithArgs(basketID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "checked_out_at", "created_at", "updated_at"}).
+			AddRow(basketID, "u1", nil, now, now))
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectBasketProducts)).
+		WithArgs(basketID).
+		WillReturnRows(products)
+}
+
+// metric returns the value of series as the metrics endpoint exposes it, or
+// 0 when it is not exposed yet.
+func (s *PostgresRepositoryTestSuite) metric(series string) float64 {
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, series+" "); ok {
+			v, err := strconv.ParseFloat(value, 64)
+			s.Require().Nil(err)
+			return v
+		}
+	}
+
+	return 0
+}
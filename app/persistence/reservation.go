@@ -0,0 +1,188 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+)
+
+func (pr *postgresRepository) CreateReservation(
+	ctx context.Context, reservation *basket.Reservation) (err error) {
+	ctx, end := startQuery(ctx, "create_reservation")
+	defer func() { end(err) }()
+
+	_, err = pr.q.ExecContext(ctx,
+		`INSERT INTO reservations (id, basket_id, product_id, quantity, stock_id, status)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		reservation.ID, reservation.BasketID, reservation.ProductID,
+		reservation.Quantity, nullString(reservation.StockID), reservation.Status,
+	)
+
+	if err != nil {
+		pr.logger.Errorf("could not create reservation: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) UpdateReservationStatus(
+	ctx context.Context, reservationID string, from basket.ReservationStatus, to basket.ReservationStatus) (_ bool, err error) {
+	ctx, end := startQuery(ctx, "update_reservation_status")
+	defer func() { end(err) }()
+
+	result, err := pr.q.ExecContext(ctx,
+		`UPDATE reservations SET status = $2, updated_at = NOW() WHERE id = $1 AND status = $3`,
+		reservationID, to, from,
+	)
+
+	if err != nil {
+		pr.logger.Errorf("could not update reservation status: %v", err)
+		return false, err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		pr.logger.Errorf("could not update reservation status: %v", err)
+		return false, err
+	}
+
+	return updated > 0, nil
+}
+
+func (pr *postgresRepository) ConfirmReservation(
+	ctx context.Context, reservationID string, stockID string) (_ bool, err error) {
+	ctx, end := startQuery(ctx, "confirm_reservation")
+	defer func() { end(err) }()
+
+	result, err := pr.q.ExecContext(ctx,
+		`UPDATE reservations SET status = $2, stock_id = $3, updated_at = NOW()
+		 WHERE id = $1 AND status = $4`,
+		reservationID, basket.ReservationStatusReserved, stockID, basket.ReservationStatusPending,
+	)
+
+	if err != nil {
+		pr.logger.Errorf("could not confirm reservation: %v", err)
+		return false, err
+	}
+
+	confirmed, err := result.RowsAffected()
+	if err != nil {
+		pr.logger.Errorf("could not confirm reservation: %v", err)
+		return false, err
+	}
+
+	return confirmed > 0, nil
+}
+
+func (pr *postgresRepository) GetReservationByID(
+	ctx context.Context, reservationID string) (_ *basket.Reservation, err error) {
+	ctx, end := startQuery(ctx, "get_reservation_by_id")
+	defer func() { end(err) }()
+
+	rows, err := pr.q.QueryContext(ctx,
+		`SELECT id, basket_id, product_id, quantity, stock_id, status, created_at, updated_at
+		FROM reservations WHERE id = $1`, reservationID)
+
+	if err != nil {
+		pr.logger.Errorf("could not get reservation by id: %v", err)
+		return nil, err
+	}
+
+	reservations, err := pr.scanReservations(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reservations) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &reservations[0], nil
+}
+
+func (pr *postgresRepository) GetReservationsByBasketID(
+	ctx context.Context, basketID string) (_ []basket.Reservation, err error) {
+	ctx, end := startQuery(ctx, "get_reservations_by_basket_id")
+	defer func() { end(err) }()
+
+	rows, err := pr.q.QueryContext(ctx,
+		`SELECT id, basket_id, product_id, quantity, stock_id, status, created_at, updated_at
+		FROM reservations WHERE basket_id = $1 ORDER BY created_at`, basketID)
+
+	if err != nil {
+		pr.logger.Errorf("could not get basket reservations: %v", err)
+		return nil, err
+	}
+
+	return pr.scanReservations(rows)
+}
+
+func (pr *postgresRepository) LockReservationsByBasketID(
+	ctx context.Context, basketID string) (_ []basket.Reservation, err error) {
+	ctx, end := startQuery(ctx, "lock_reservations_by_basket_id")
+	defer func() { end(err) }()
+
+	rows, err := pr.q.QueryContext(ctx,
+		`SELECT id, basket_id, product_id, quantity, stock_id, status, created_at, updated_at
+		FROM reservations WHERE basket_id = $1 ORDER BY created_at FOR UPDATE`, basketID)
+
+	if err != nil {
+		pr.logger.Errorf("could not lock basket reservations: %v", err)
+		return nil, err
+	}
+
+	return pr.scanReservations(rows)
+}
+
+func (pr *postgresRepository) GetReservationsByStatus(
+	ctx context.Context, status basket.ReservationStatus, unchangedFor time.Duration) (_ []basket.Reservation, err error) {
+	ctx, end := startQuery(ctx, "get_reservations_by_status")
+	defer func() { end(err) }()
+
+	rows, err := pr.q.QueryContext(ctx,
+		`SELECT id, basket_id, product_id, quantity, stock_id, status, created_at, updated_at
+		FROM reservations WHERE status = $1 AND updated_at <= NOW() - $2 * INTERVAL '1 millisecond'
+		ORDER BY created_at`,
+		status, unchangedFor.Milliseconds())
+
+	if err != nil {
+		pr.logger.Errorf("could not get reservations by status: %v", err)
+		return nil, err
+	}
+
+	return pr.scanReservations(rows)
+}
+
+func (pr *postgresRepository) scanReservations(rows *sql.Rows) ([]basket.Reservation, error) {
+	defer rows.Close()
+
+	var reservations []basket.Reservation
+	for rows.Next() {
+		var reservation basket.Reservation
+		var stockID sql.NullString
+		if err := rows.Scan(
+			&reservation.ID,
+			&reservation.BasketID,
+			&reservation.ProductID,
+			&reservation.Quantity,
+			&stockID,
+			&reservation.Status,
+			&reservation.CreatedAt,
+			&reservation.UpdatedAt,
+		); err != nil {
+			pr.logger.Errorf("could not scan reservation: %v", err)
+			return nil, err
+		}
+		reservation.StockID = stockID.String
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"database/sql"
+
+	"github.com/pact-cdc-example/basket-service/app/basket"
+)
+
+// productSnapshotColumns maps the nullable snapshot columns of basket_products,
+// lines added before snapshots were stored have none.
+type productSnapshotColumns struct {
+	name        sql.NullString
+	code        sql.NullString
+	price       sql.NullFloat64
+	imageURL    sql.NullString
+	productType sql.NullString
+}
+
+func newProductSnapshotColumns(snapshot *basket.ProductSnapshot) productSnapshotColumns {
+	if snapshot == nil {
+		return productSnapshotColumns{}
+	}
+
+	return productSnapshotColumns{
+		name:        sql.NullString{String: snapshot.Name, Valid: true},
+		code:        sql.NullString{String: snapshot.Code, Valid: true},
+		price:       sql.NullFloat64{Float64: snapshot.Price, Valid: true},
+		imageURL:    sql.NullString{String: snapshot.ImageURL, Valid: true},
+		productType: sql.NullString{String: snapshot.Type, Valid: true},
+	}
+}
+
+func (c productSnapshotColumns) toSnapshot() *basket.ProductSnapshot {
+	if !c.name.Valid {
+		return nil
+	}
+
+	return &basket.ProductSnapshot{
+		Name:     c.name.String,
+		Code:     c.code.String,
+		Price:    c.price.Float64,
+		ImageURL: c.imageURL.String,
+		Type:     c.productType.String,
+	}
+}
@@ -0,0 +1,307 @@
+package product
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
+	"github.com/pact-cdc-example/basket-service/pkg/reqctx"
+)
+
+const (
+	defaultBatchWindow       = 5 * time.Millisecond
+	defaultBatchMaxSize      = 100
+	defaultBatchFetchTimeout = 10 * time.Second
+)
+
+// call is a single product lookup shared by every caller asking for the
+// same id while it is queued or in flight.
+type call struct {
+	done    chan struct{}
+	product *Product
+	cc      httpclient.CacheControl
+	err     error
+}
+
+type batchingClient struct {
+	next         Client
+	window       time.Duration
+	maxBatchSize int
+	timeout      time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*call
+	queue []string
+	// queueCtx is the context of the caller which started the queue, the
+	// batch is fetched with its values so it is logged and traced as part
+	// of that request.
+	queueCtx context.Context
+	timer    *time.Timer
+}
+
+type NewBatchingClientOpts struct {
+	Client       Client
+	Window       time.Duration
+	MaxBatchSize int
+	Timeout      time.Duration
+}
+
+// NewBatchingClient returns a client which collects the ids asked within
+// window and fetches them with bulk requests of at most MaxBatchSize ids.
+// Batches carry the values of the context of the caller which started them
+// but are not cancelled with it, a caller which gives up only stops waiting
+// for the result.
+func NewBatchingClient(opts *NewBatchingClientOpts) Client {
+	b := &batchingClient{
+		next:         opts.Client,
+		window:       opts.Window,
+		maxBatchSize: opts.MaxBatchSize,
+		timeout:      opts.Timeout,
+		calls:        make(map[string]*call),
+	}
+
+	if b.window <= 0 {
+		b.window = defaultBatchWindow
+	}
+	if b.maxBatchSize <= 0 {
+		b.maxBatchSize = defaultBatchMaxSize
+	}
+	if b.timeout <= 0 {
+		b.timeout = defaultBatchFetchTimeout
+	}
+
+	return b
+}
+
+func (b *batchingClient) GetProductByID(ctx context.Context, id string) (*Product, error) {
+	prod, _, err := b.getProductByID(ctx, id, "")
+	return prod, err
+}
+
+func (b *batchingClient) GetProductsByIDs(
+	ctx context.Context, req GetProductByIDsRequest) ([]Product, error) {
+	products, _, err := b.getProductsByIDs(ctx, req)
+	return products, err
+}
+
+// getProductByID lets a caching client in front of the batcher revalidate its
+// entries. Conditional requests are sent on their own, the bulk endpoint can
+// not answer them.
+func (b *batchingClient) getProductByID(
+	ctx context.Context, id string, etag string) (*Product, httpclient.CacheControl, error) {
+	if rc, ok := b.next.(revalidatingClient); ok && etag != "" {
+		return rc.getProductByID(ctx, id, etag)
+	}
+
+	return b.wait(ctx, b.load(ctx, id))
+}
+
+func (b *batchingClient) getProductsByIDs(
+	ctx context.Context, req GetProductByIDsRequest) ([]Product, httpclient.CacheControl, error) {
+	calls := make([]*call, len(req.IDs))
+	for i, id := range req.IDs {
+		calls[i] = b.load(ctx, id)
+	}
+
+	var cc httpclient.CacheControl
+	products := make([]Product, 0, len(calls))
+	for i, c := range calls {
+		prod, callCC, err := b.wait(ctx, c)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil, httpclient.CacheControl{}, SomeProductsNotFound()
+			}
+			return nil, httpclient.CacheControl{}, err
+		}
+
+		if i == 0 {
+			cc = callCC
+		} else {
+			cc = mergeCacheControl(cc, callCC)
+		}
+		products = append(products, *prod)
+	}
+
+	return products, cc, nil
+}
+
+func (b *batchingClient) load(ctx context.Context, id string) *call {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.calls[id]; ok {
+		return c
+	}
+
+	c := &call{done: make(chan struct{})}
+	b.calls[id] = c
+
+	if len(b.queue) == 0 {
+		b.queueCtx = ctx
+	}
+	b.queue = append(b.queue, id)
+
+	if len(b.queue) >= b.maxBatchSize {
+		b.flushLocked()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.window, b.flush)
+	}
+
+	return c
+}
+
+func (b *batchingClient) wait(ctx context.Context, c *call) (*Product, httpclient.CacheControl, error) {
+	select {
+	case <-c.done:
+		if c.err != nil {
+			return nil, httpclient.CacheControl{}, c.err
+		}
+		prod := *c.product
+		return &prod, c.cc, nil
+	case <-ctx.Done():
+		return nil, httpclient.CacheControl{}, ctx.Err()
+	}
+}
+
+func (b *batchingClient) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flushLocked()
+}
+
+func (b *batchingClient) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	ids, ctx := b.queue, b.queueCtx
+	b.queue, b.queueCtx = nil, nil
+
+	if len(ids) == 0 {
+		return
+	}
+
+	ctx = reqctx.Detach(ctx)
+	for start := 0; start < len(ids); start += b.maxBatchSize {
+		end := start + b.maxBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		go b.fetch(ctx, ids[start:end])
+	}
+}
+
+func (b *batchingClient) fetch(ctx context.Context, ids []string) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	if len(ids) == 1 {
+		// a single id is fetched from the single product endpoint, its etag
+		// lets a caching client in front of the batcher revalidate it.
+		b.fetchEach(ctx, ids)
+		return
+	}
+
+	products, cc, err := b.fetchProducts(ctx, ids)
+	if err != nil && IsNotFound(err) && len(ids) > 1 {
+		// the bulk endpoint rejects the whole batch when one of the ids is
+		// unknown, resolve them one by one so only the unknown ones fail.
+		b.fetchEach(ctx, ids)
+		return
+	}
+
+	// the etag of a bulk response does not identify a single product
+	cc.ETag = ""
+
+	found := make(map[string]Product, len(products))
+	for _, prod := range products {
+		found[prod.ID] = prod
+	}
+
+	for _, id := range ids {
+		if err != nil {
+			b.resolve(id, nil, cc, err)
+			continue
+		}
+
+		prod, ok := found[id]
+		if !ok {
+			b.resolve(id, nil, cc, ProductNotFound())
+			continue
+		}
+		b.resolve(id, &prod, cc, nil)
+	}
+}
+
+func (b *batchingClient) fetchEach(ctx context.Context, ids []string) {
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			prod, cc, err := b.fetchProduct(ctx, id)
+			b.resolve(id, prod, cc, err)
+		}(id)
+	}
+	wg.Wait()
+}
+
+func (b *batchingClient) fetchProducts(
+	ctx context.Context, ids []string) ([]Product, httpclient.CacheControl, error) {
+	req := GetProductByIDsRequest{IDs: ids}
+	if rc, ok := b.next.(revalidatingClient); ok {
+		return rc.getProductsByIDs(ctx, req)
+	}
+
+	products, err := b.next.GetProductsByIDs(ctx, req)
+	return products, httpclient.CacheControl{}, err
+}
+
+func (b *batchingClient) fetchProduct(ctx context.Context, id string) (*Product, httpclient.CacheControl, error) {
+	if rc, ok := b.next.(revalidatingClient); ok {
+		return rc.getProductByID(ctx, id, "")
+	}
+
+	prod, err := b.next.GetProductByID(ctx, id)
+	return prod, httpclient.CacheControl{}, err
+}
+
+func (b *batchingClient) resolve(id string, prod *Product, cc httpclient.CacheControl, err error) {
+	b.mu.Lock()
+	c, ok := b.calls[id]
+	delete(b.calls, id)
+	b.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	c.product, c.cc, c.err = prod, cc, err
+	close(c.done)
+}
+
+// mergeCacheControl returns the directives which satisfy both a and b, the
+// products of a bulk lookup may come from batches with different directives.
+func mergeCacheControl(a httpclient.CacheControl, b httpclient.CacheControl) httpclient.CacheControl {
+	merged := httpclient.CacheControl{
+		NoStore: a.NoStore || b.NoStore,
+		NoCache: a.NoCache || b.NoCache,
+	}
+
+	switch {
+	case a.HasMaxAge && b.HasMaxAge:
+		merged.MaxAge, merged.HasMaxAge = a.MaxAge, true
+		if b.MaxAge < a.MaxAge {
+			merged.MaxAge = b.MaxAge
+		}
+	case a.HasMaxAge:
+		merged.MaxAge, merged.HasMaxAge = a.MaxAge, true
+	case b.HasMaxAge:
+		merged.MaxAge, merged.HasMaxAge = b.MaxAge, true
+	}
+
+	return merged
+}
@@ -0,0 +1,164 @@
+package product_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/app/product"
+	"github.com/pact-cdc-example/basket-service/pkg/reqctx"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeProductClient struct {
+	mu         sync.Mutex
+	batches    [][]string
+	singles    []string
+	requestIDs []string
+	missing    map[string]bool
+	// started and release block the bulk requests when set, so a test
+	// can act while a batch is in flight.
+	started chan struct{}
+	release chan struct{}
+	errs    []error
+}
+
+func (f *fakeProductClient) GetProductByID(ctx context.Context, id string) (*product.Product, error) {
+	f.mu.Lock()
+	f.singles = append(f.singles, id)
+	f.requestIDs = append(f.requestIDs, reqctx.RequestID(ctx))
+	f.mu.Unlock()
+
+	if f.missing[id] {
+		return nil, product.ProductNotFound()
+	}
+
+	return &product.Product{ID: id}, nil
+}
+
+func (f *fakeProductClient) GetProductsByIDs(
+	ctx context.Context, req product.GetProductByIDsRequest) ([]product.Product, error) {
+	if f.started != nil {
+		f.started <- struct{}{}
+		<-f.release
+	}
+
+	f.mu.Lock()
+	f.batches = append(f.batches, req.IDs)
+	f.requestIDs = append(f.requestIDs, reqctx.RequestID(ctx))
+	f.errs = append(f.errs, ctx.Err())
+	f.mu.Unlock()
+
+	products := make([]product.Product, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if f.missing[id] {
+			return nil, product.SomeProductsNotFound()
+		}
+		products = append(products, product.Product{ID: id})
+	}
+
+	return products, nil
+}
+
+type BatchingClientTestSuite struct {
+	suite.Suite
+	next *fakeProductClient
+}
+
+func TestBatchingClientTestSuite(t *testing.T) {
+	suite.Run(t, new(BatchingClientTestSuite))
+}
+
+func (s *BatchingClientTestSuite) SetupTest() {
+	s.next = &fakeProductClient{missing: map[string]bool{}}
+}
+
+// newClient returns a batcher which only flushes full batches, the window
+// is too long to elapse within a test.
+func (s *BatchingClientTestSuite) newClient(maxBatchSize int) product.Client {
+	return product.NewBatchingClient(&product.NewBatchingClientOpts{
+		Client:       s.next,
+		Window:       time.Hour,
+		MaxBatchSize: maxBatchSize,
+	})
+}
+
+func (s *BatchingClientTestSuite) TestGivenDuplicateIDsThenItShouldCoalesceThem() {
+	products, err := s.newClient(2).GetProductsByIDs(context.Background(), product.GetProductByIDsRequest{
+		IDs: []string{"p1", "p1", "p2"},
+	})
+
+	s.Nil(err)
+	s.Equal([]product.Product{{ID: "p1"}, {ID: "p1"}, {ID: "p2"}}, products)
+	s.Equal([][]string{{"p1", "p2"}}, s.next.batches)
+}
+
+func (s *BatchingClientTestSuite) TestGivenMoreIDsThanMaxBatchSizeThenItShouldSplitRequests() {
+	products, err := s.newClient(2).GetProductsByIDs(context.Background(), product.GetProductByIDsRequest{
+		IDs: []string{"p1", "p2", "p3", "p4"},
+	})
+
+	s.Nil(err)
+	s.Len(products, 4)
+	s.Equal("p4", products[3].ID)
+	s.ElementsMatch([][]string{{"p1", "p2"}, {"p3", "p4"}}, s.next.batches)
+}
+
+func (s *BatchingClientTestSuite) TestGivenSingleIDThenItShouldBeFetchedFromSingleProductEndpoint() {
+	prod, err := s.newClient(1).GetProductByID(context.Background(), "p1")
+
+	s.Nil(err)
+	s.Equal("p1", prod.ID)
+	s.Empty(s.next.batches)
+	s.Equal([]string{"p1"}, s.next.singles)
+}
+
+func (s *BatchingClientTestSuite) TestGivenUnknownIDInBatchThenItShouldFailOnlyThatID() {
+	s.next.missing["p2"] = true
+	client := s.newClient(2)
+
+	var wg sync.WaitGroup
+	var errKnown, errUnknown error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, errKnown = client.GetProductByID(context.Background(), "p1")
+	}()
+	go func() {
+		defer wg.Done()
+		_, errUnknown = client.GetProductByID(context.Background(), "p2")
+	}()
+	wg.Wait()
+
+	s.Nil(errKnown)
+	s.True(product.IsNotFound(errUnknown))
+}
+
+func (s *BatchingClientTestSuite) TestGivenCallerContextThenBatchShouldCarryItsValuesButNotItsCancellation() {
+	s.next.started = make(chan struct{})
+	s.next.release = make(chan struct{})
+	client := s.newClient(2)
+
+	ctx, cancel := context.WithCancel(reqctx.WithRequestID(context.Background(), "req-1"))
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.GetProductsByIDs(ctx, product.GetProductByIDsRequest{IDs: []string{"p1", "p2"}})
+		errs <- err
+	}()
+
+	<-s.next.started
+	cancel()
+	s.ErrorIs(<-errs, context.Canceled)
+
+	close(s.next.release)
+	s.Eventually(func() bool {
+		s.next.mu.Lock()
+		defer s.next.mu.Unlock()
+		return len(s.next.batches) == 1
+	}, time.Second, time.Millisecond)
+
+	s.Equal([]string{"req-1"}, s.next.requestIDs)
+	s.Equal([]error{nil}, s.next.errs)
+}
@@ -0,0 +1,240 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
+	"github.com/pact-cdc-example/basket-service/pkg/lru"
+)
+
+const (
+	defaultCacheSize        = 1024
+	defaultCacheTTL         = time.Minute
+	defaultCacheNegativeTTL = 10 * time.Second
+)
+
+var errUnexpectedNotModified = errors.New("product api answered an unconditional request with not modified")
+
+// CacheMetrics is notified on every lookup of the caching client so the
+// hit ratio can be exported.
+type CacheMetrics interface {
+	CacheHit()
+	CacheMiss()
+}
+
+// revalidatingClient is implemented by clients which expose the caching
+// headers of the product api, the caching client falls back to its own
+// ttl for the ones which don't.
+type revalidatingClient interface {
+	getProductByID(ctx context.Context, id string, etag string) (*Product, httpclient.CacheControl, error)
+	getProductsByIDs(ctx context.Context, req GetProductByIDsRequest) ([]Product, httpclient.CacheControl, error)
+}
+
+type cacheEntry struct {
+	product   *Product
+	etag      string
+	expiresAt time.Time
+}
+
+type cachingClient struct {
+	next        Client
+	entries     *lru.Cache[string, cacheEntry]
+	ttl         time.Duration
+	negativeTTL time.Duration
+	metrics     CacheMetrics
+	now         func() time.Time
+}
+
+type NewCachingClientOpts struct {
+	Client      Client
+	Size        int
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	Metrics     CacheMetrics
+}
+
+func NewCachingClient(opts *NewCachingClientOpts) Client {
+	size := opts.Size
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+
+	c := &cachingClient{
+		next:        opts.Client,
+		entries:     lru.New[string, cacheEntry](size),
+		ttl:         opts.TTL,
+		negativeTTL: opts.NegativeTTL,
+		metrics:     opts.Metrics,
+		now:         time.Now,
+	}
+
+	if c.ttl <= 0 {
+		c.ttl = defaultCacheTTL
+	}
+	if c.negativeTTL <= 0 {
+		c.negativeTTL = defaultCacheNegativeTTL
+	}
+
+	return c
+}
+
+func (c *cachingClient) GetProductByID(ctx context.Context, id string) (*Product, error) {
+	entry, found := c.entries.Get(id)
+	if found && c.now().Before(entry.expiresAt) {
+		c.hit()
+		if entry.product == nil {
+			return nil, ProductNotFound()
+		}
+		prod := *entry.product
+		return &prod, nil
+	}
+
+	c.miss()
+
+	rc, ok := c.next.(revalidatingClient)
+	if !ok {
+		prod, err := c.next.GetProductByID(ctx, id)
+		if err != nil {
+			c.storeNotFound(id, err)
+			return nil, err
+		}
+		c.store(*prod, httpclient.CacheControl{})
+		return prod, nil
+	}
+
+	var etag string
+	if found && entry.product != nil {
+		etag = entry.etag
+	}
+
+	prod, cc, err := rc.getProductByID(ctx, id, etag)
+	if err != nil {
+		c.storeNotFound(id, err)
+		return nil, err
+	}
+
+	if prod == nil {
+		if etag == "" {
+			return nil, errUnexpectedNotModified
+		}
+		// not modified, the cached copy is still valid
+		cc.ETag = etag
+		c.store(*entry.product, cc)
+		cached := *entry.product
+		return &cached, nil
+	}
+
+	c.store(*prod, cc)
+	return prod, nil
+}
+
+func (c *cachingClient) GetProductsByIDs(
+	ctx context.Context, req GetProductByIDsRequest) ([]Product, error) {
+	now := c.now()
+
+	cached := make(map[string]Product, len(req.IDs))
+	var missingIDs []string
+	for _, id := range req.IDs {
+		entry, found := c.entries.Get(id)
+		if !found || !now.Before(entry.expiresAt) {
+			c.miss()
+			missingIDs = append(missingIDs, id)
+			continue
+		}
+
+		c.hit()
+		if entry.product == nil {
+			return nil, SomeProductsNotFound()
+		}
+		cached[id] = *entry.product
+	}
+
+	if len(missingIDs) > 0 {
+		fetched, cc, err := c.fetchProducts(ctx, GetProductByIDsRequest{IDs: missingIDs})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, prod := range fetched {
+			c.store(prod, cc)
+			cached[prod.ID] = prod
+		}
+
+		notFound := false
+		for _, id := range missingIDs {
+			if _, ok := cached[id]; !ok {
+				c.entries.Add(id, cacheEntry{expiresAt: c.now().Add(c.negativeTTL)})
+				notFound = true
+			}
+		}
+
+		// answer like a negative hit does, the first lookup of an unknown
+		// id must not differ from the cached ones.
+		if notFound {
+			return nil, SomeProductsNotFound()
+		}
+	}
+
+	products := make([]Product, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if prod, ok := cached[id]; ok {
+			products = append(products, prod)
+		}
+	}
+
+	return products, nil
+}
+
+func (c *cachingClient) fetchProducts(
+	ctx context.Context, req GetProductByIDsRequest) ([]Product, httpclient.CacheControl, error) {
+	if rc, ok := c.next.(revalidatingClient); ok {
+		return rc.getProductsByIDs(ctx, req)
+	}
+
+	products, err := c.next.GetProductsByIDs(ctx, req)
+	return products, httpclient.CacheControl{}, err
+}
+
+func (c *cachingClient) store(prod Product, cc httpclient.CacheControl) {
+	if cc.NoStore {
+		c.entries.Remove(prod.ID)
+		return
+	}
+
+	ttl := c.ttl
+	if cc.HasMaxAge {
+		ttl = cc.MaxAge
+	}
+	if cc.NoCache {
+		// keep the entry for revalidation only
+		ttl = 0
+	}
+
+	c.entries.Add(prod.ID, cacheEntry{
+		product:   &prod,
+		etag:      cc.ETag,
+		expiresAt: c.now().Add(ttl),
+	})
+}
+
+func (c *cachingClient) storeNotFound(id string, err error) {
+	if !IsNotFound(err) {
+		return
+	}
+
+	c.entries.Add(id, cacheEntry{expiresAt: c.now().Add(c.negativeTTL)})
+}
+
+func (c *cachingClient) hit() {
+	if c.metrics != nil {
+		c.metrics.CacheHit()
+	}
+}
+
+func (c *cachingClient) miss() {
+	if c.metrics != nil {
+		c.metrics.CacheMiss()
+	}
+}
@@ -0,0 +1,198 @@
+package product_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/basket-service/app/product"
+	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
+	"github.com/stretchr/testify/suite"
+)
+
+type CachingClientTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	requests    int32
+	notModified int32
+	headers     http.Header
+	client      product.Client
+}
+
+func TestCachingClientTestSuite(t *testing.T) {
+	suite.Run(t, new(CachingClientTestSuite))
+}
+
+func (s *CachingClientTestSuite) SetupTest() {
+	s.requests = 0
+	s.notModified = 0
+	s.headers = http.Header{}
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.requests, 1)
+
+		for k, v := range s.headers {
+			w.Header()[k] = v
+		}
+		w.Header().Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/products/")
+		if id == "missing" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(product.ProductNotFound())
+			return
+		}
+
+		if etag := s.headers.Get(fiber.HeaderETag); etag != "" && r.Header.Get(fiber.HeaderIfNoneMatch) == etag {
+			atomic.AddInt32(&s.notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(product.Product{ID: id})
+	}))
+
+	s.client = product.NewCachingClient(&product.NewCachingClientOpts{
+		Client: product.NewClient(&product.NewClientOpts{
+			HTTPClient: httpclient.New(),
+			BaseURL:    s.server.URL,
+		}),
+	})
+}
+
+func (s *CachingClientTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *CachingClientTestSuite) TestGivenCachedProductThenItShouldNotCallProductAPIAgain() {
+	for i := 0; i < 3; i++ {
+		prod, err := s.client.GetProductByID(context.Background(), "p1")
+		s.Nil(err)
+		s.Equal("p1", prod.ID)
+	}
+
+	s.Equal(int32(1), atomic.LoadInt32(&s.requests))
+}
+
+func (s *CachingClientTestSuite) TestGivenNotFoundProductThenItShouldCacheNegativeResult() {
+	for i := 0; i < 2; i++ {
+		_, err := s.client.GetProductByID(context.Background(), "missing")
+		s.True(product.IsNotFound(err))
+	}
+
+	s.Equal(int32(1), atomic.LoadInt32(&s.requests))
+}
+
+func (s *CachingClientTestSuite) TestGivenNoStoreDirectiveThenItShouldNotCacheProduct() {
+	s.headers.Set(fiber.HeaderCacheControl, "no-store")
+
+	for i := 0; i < 2; i++ {
+		_, err := s.client.GetProductByID(context.Background(), "p1")
+		s.Nil(err)
+	}
+
+	s.Equal(int32(2), atomic.LoadInt32(&s.requests))
+}
+
+func (s *CachingClientTestSuite) TestGivenNoCacheDirectiveThenItShouldRevalidateWithETag() {
+	s.headers.Set(fiber.HeaderCacheControl, "no-cache")
+	s.headers.Set(fiber.HeaderETag, `"v1"`)
+
+	for i := 0; i < 2; i++ {
+		prod, err := s.client.GetProductByID(context.Background(), "p1")
+		s.Nil(err)
+		s.Equal("p1", prod.ID)
+	}
+
+	s.Equal(int32(2), atomic.LoadInt32(&s.requests))
+}
+
+func (s *CachingClientTestSuite) TestGivenBatcherBehindCacheThenItShouldStillRevalidateWithETag() {
+	s.headers.Set(fiber.HeaderCacheControl, "no-cache")
+	s.headers.Set(fiber.HeaderETag, `"v1"`)
+
+	client := product.NewCachingClient(&product.NewCachingClientOpts{
+		Client: product.NewBatchingClient(&product.NewBatchingClientOpts{
+			Client: product.NewClient(&product.NewClientOpts{
+				HTTPClient: httpclient.New(),
+				BaseURL:    s.server.URL,
+			}),
+			MaxBatchSize: 1,
+		}),
+	})
+
+	for i := 0; i < 2; i++ {
+		prod, err := client.GetProductByID(context.Background(), "p1")
+		s.Nil(err)
+		s.Equal("p1", prod.ID)
+	}
+
+	s.Equal(int32(2), atomic.LoadInt32(&s.requests))
+	s.Equal(int32(1), atomic.LoadInt32(&s.notModified))
+}
+
+// partialProductClient answers bulk requests with the known products only,
+// like a catalog which drops unknown ids instead of rejecting the request.
+type partialProductClient struct {
+	requests int32
+	missing  map[string]bool
+}
+
+func (f *partialProductClient) GetProductByID(_ context.Context, id string) (*product.Product, error) {
+	atomic.AddInt32(&f.requests, 1)
+	if f.missing[id] {
+		return nil, product.ProductNotFound()
+	}
+
+	return &product.Product{ID: id}, nil
+}
+
+func (f *partialProductClient) GetProductsByIDs(
+	_ context.Context, req product.GetProductByIDsRequest) ([]product.Product, error) {
+	atomic.AddInt32(&f.requests, 1)
+
+	products := make([]product.Product, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if !f.missing[id] {
+			products = append(products, product.Product{ID: id})
+		}
+	}
+
+	return products, nil
+}
+
+func (s *CachingClientTestSuite) TestGivenUnknownIDInBulkLookupThenEveryLookupShouldFailTheSameWay() {
+	next := &partialProductClient{missing: map[string]bool{"missing": true}}
+	client := product.NewCachingClient(&product.NewCachingClientOpts{Client: next})
+
+	for i := 0; i < 2; i++ {
+		products, err := client.GetProductsByIDs(context.Background(), product.GetProductByIDsRequest{
+			IDs: []string{"p1", "missing"},
+		})
+		s.Nil(products)
+		s.True(product.IsNotFound(err))
+	}
+
+	s.Equal(int32(1), atomic.LoadInt32(&next.requests))
+}
+
+func (s *CachingClientTestSuite) TestGivenCachedProductsThenBulkLookupShouldOnlyFetchTheOthers() {
+	next := &partialProductClient{}
+	client := product.NewCachingClient(&product.NewCachingClientOpts{Client: next})
+
+	_, err := client.GetProductByID(context.Background(), "p1")
+	s.Require().Nil(err)
+
+	products, err := client.GetProductsByIDs(context.Background(), product.GetProductByIDsRequest{
+		IDs: []string{"p1", "p2"},
+	})
+
+	s.Nil(err)
+	s.Equal([]product.Product{{ID: "p1"}, {ID: "p2"}}, products)
+	s.Equal(int32(2), atomic.LoadInt32(&next.requests))
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
 
 	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
 )
@@ -38,34 +41,62 @@ const (
 )
 
 func (c *client) GetProductByID(ctx context.Context, id string) (*Product, error) {
+	prod, _, err := c.getProductByID(ctx, id, "")
+	return prod, err
+}
+
+func (c *client) GetProductsByIDs(
+	ctx context.Context, req GetProductByIDsRequest) ([]Product, error) {
+	products, _, err := c.getProductsByIDs(ctx, req)
+	return products, err
+}
+
+// getProductByID sends a conditional request when etag is given. A nil product
+// with a nil error means the product has not been modified since etag.
+func (c *client) getProductByID(
+	ctx context.Context, id string, etag string) (*Product, httpclient.CacheControl, error) {
 	url := fmt.Sprintf(getProductByIDPath, c.baseURL, id)
 
-	resBytes, err := c.httpClient.Get(ctx, url, c.headers)
+	headers := c.headers
+	if etag != "" {
+		headers = make(map[string]string, len(c.headers)+1)
+		for k, v := range c.headers {
+			headers[k] = v
+		}
+		headers[fiber.HeaderIfNoneMatch] = etag
+	}
+
+	res, err := c.httpClient.Do(ctx, http.MethodGet, url, headers, nil)
 	if err != nil {
-		return nil, err
+		return nil, httpclient.CacheControl{}, err
+	}
+
+	cc := httpclient.ParseCacheControl(res.Header)
+	if res.NotModified() {
+		return nil, cc, nil
 	}
 
-	var resp GetProductResponse
-	if err := json.Unmarshal(resBytes, &resp); err != nil {
-		return nil, err
+	var prod Product
+	if err := json.Unmarshal(res.Body, &prod); err != nil {
+		return nil, cc, err
 	}
 
-	return &resp.Product, nil
+	return &prod, cc, nil
 }
 
-func (c *client) GetProductsByIDs(
-	ctx context.Context, req GetProductByIDsRequest) ([]Product, error) {
+func (c *client) getProductsByIDs(
+	ctx context.Context, req GetProductByIDsRequest) ([]Product, httpclient.CacheControl, error) {
 	url := fmt.Sprintf(getProductsByIDsPath, c.baseURL)
 
-	resBytes, err := c.httpClient.Post(ctx, url, c.headers, req)
+	res, err := c.httpClient.Do(ctx, http.MethodPost, url, c.headers, req)
 	if err != nil {
-		return nil, err
+		return nil, httpclient.CacheControl{}, err
 	}
 
 	var resp GetProductsResponse
-	if err := json.Unmarshal(resBytes, &resp); err != nil {
-		return nil, err
+	if err := json.Unmarshal(res.Body, &resp); err != nil {
+		return nil, httpclient.CacheControl{}, err
 	}
 
-	return resp.Products, nil
+	return resp.Products, httpclient.ParseCacheControl(res.Header), nil
 }
@@ -81,7 +81,7 @@ func (s *ProductConsumerTestSuite) TestGivenGetProductByIDRequestThenItShouldRet
 
 	err := s.pact.Verify(test)
 
-	s.Equal(err, cerr.Bag{Code: 20001, Message: "Product not found."})
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 20001, Message: "Product not found."}}, err)
 }
 
 func (s *ProductConsumerTestSuite) TestGivenGetProductByIDRequestThenItShouldReturnProductWhenGivenProductIDExists() {
@@ -164,7 +164,7 @@ func (s *ProductConsumerTestSuite) TestGivenGetProductsByIDsReqThenItShouldRetur
 
 	err := s.pact.Verify(test)
 
-	s.Equal(err, cerr.Bag{Code: 10001, Message: "could not parse request body."})
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 10001, Message: "could not parse request body."}}, err)
 }
 
 func (s *ProductConsumerTestSuite) TestGivenGetProductsByIDsReqThenItShouldReturnProductNotFoundErrorWhenOneOrMoreGivenProductIDNotExists() {
@@ -205,10 +205,10 @@ func (s *ProductConsumerTestSuite) TestGivenGetProductsByIDsReqThenItShouldRetur
 	}
 
 	err := s.pact.Verify(test)
-	s.Equal(err, cerr.Bag{Code: 20003, Message: "At least one of given product ids does not exist."})
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 20003, Message: "At least one of given product ids does not exist."}}, err)
 }
 
-func (s *ProductConsumerTestSuite) NoTestGivenGetProductsByIDsReqThenItShouldReturnProductsWhenAllGivenProductIDsExists() {
+func (s *ProductConsumerTestSuite) TestGivenGetProductsByIDsReqThenItShouldReturnProductsWhenAllGivenProductIDsExists() {
 	givenProductIDs := []string{gofakeit.UUID(), gofakeit.UUID(), gofakeit.UUID()}
 
 	givenReq := product.GetProductByIDsRequest{
@@ -255,10 +255,14 @@ func (s *ProductConsumerTestSuite) NoTestGivenGetProductsByIDsReqThenItShouldRet
 		Given("i get products with given ids").
 		UponReceiving("A request for get products with given ids").
 		WithRequest(dsl.Request{
-			Method: http.MethodGet,
+			Method: http.MethodPost,
 			Path:   dsl.String(getProductsByIDsPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
 			Body: dsl.StructMatcher{
-				"ids": dsl.Like(givenProductIDs),
+				"ids": dsl.EachLike(givenProductIDs[0], 1),
 			},
 		}).
 		WillRespondWith(dsl.Response{
@@ -268,27 +272,30 @@ func (s *ProductConsumerTestSuite) NoTestGivenGetProductsByIDsReqThenItShouldRet
 			},
 			Body: dsl.StructMatcher{
 				"products": dsl.EachLike(dsl.StructMatcher{
-					"id":        dsl.Like(givenProducts[0].ID),
-					"name":      dsl.Like(givenProducts[0].Name),
-					"code":      dsl.Like(givenProducts[0].Code),
-					"color":     dsl.Like(givenProducts[0].Color),
-					"createdAt": dsl.Like(givenProducts[0].CreatedAt),
-					"updatedAt": dsl.Like(givenProducts[0].UpdatedAt),
-					"price":     dsl.Like(givenProducts[0].Price),
-					"imageURL":  dsl.Like(givenProducts[0].ImageURL),
-					"type":      dsl.Like(givenProducts[0].Type),
+					"id":         dsl.Like(givenProducts[0].ID),
+					"name":       dsl.Like(givenProducts[0].Name),
+					"code":       dsl.Like(givenProducts[0].Code),
+					"color":      dsl.Like(givenProducts[0].Color),
+					"created_at": dsl.Like(givenProducts[0].CreatedAt),
+					"updated_at": dsl.Like(givenProducts[0].UpdatedAt),
+					"price":      dsl.Like(givenProducts[0].Price),
+					"image_url":  dsl.Like(givenProducts[0].ImageURL),
+					"type":       dsl.Like(givenProducts[0].Type),
 				}, len(givenProducts)),
 			},
 		})
 
+	var products []product.Product
 	var test = func() error {
-		_, err := s.client.GetProductsByIDs(context.Background(), givenReq)
+		var err error
+		products, err = s.client.GetProductsByIDs(context.Background(), givenReq)
 		return err
 	}
 
 	err := s.pact.Verify(test)
 
 	s.Nil(err)
+	s.Len(products, len(givenProducts))
 }
 
 func (s *ProductConsumerTestSuite) initPact() {
@@ -299,7 +306,7 @@ func (s *ProductConsumerTestSuite) initPact() {
 		DisableToolValidityCheck: true,
 		PactFileWriteMode:        "overwrite",
 		LogDir:                   "./pacts/logs",
-		PactDir:                  ".././pacts",
+		PactDir:                  "./pacts",
 	}
 	//it must be used otherwise it could not create pact file
 	s.pact.Setup(true)
@@ -0,0 +1,54 @@
+package product_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/app/product"
+	"github.com/pact-cdc-example/basket-service/pkg/pactdrift"
+	"github.com/pact-cdc-example/basket-service/pkg/pactstub"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProductContractDriftTestSuite struct {
+	suite.Suite
+}
+
+func TestProductContractDriftTestSuite(t *testing.T) {
+	suite.Run(t, new(ProductContractDriftTestSuite))
+}
+
+func (s *ProductContractDriftTestSuite) TestGivenCommittedPactsThenClientTypesShouldMatchThem() {
+	pacts, err := pactstub.Load("./pacts/*.json")
+	s.Require().Nil(err)
+	s.Require().NotEmpty(pacts)
+
+	mismatches := pactdrift.Check(pacts, []pactdrift.Contract{
+		{
+			Description: "A request for product with a exist product id",
+			Response:    product.Product{},
+		},
+		{
+			Description: "A request for product with a non exist product id",
+			Response:    product.Product{},
+		},
+		{
+			Description: "A request for get products",
+			Request:     product.GetProductByIDsRequest{},
+			Response:    product.GetProductsResponse{},
+		},
+		{
+			Description: "A request for get products with given ids",
+			Request:     product.GetProductByIDsRequest{},
+			Response:    product.GetProductsResponse{},
+		},
+		{
+			Description: "A request for get products contains at least one not exist product id",
+			Request:     product.GetProductByIDsRequest{},
+			Response:    product.GetProductsResponse{},
+		},
+	})
+
+	for _, mismatch := range mismatches {
+		s.Fail(mismatch.String())
+	}
+}
@@ -0,0 +1,48 @@
+package product
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+)
+
+// product service specific errors
+
+const (
+	ProductNotFoundErrCode      cerr.Code = 20001
+	SomeProductsNotFoundErrCode cerr.Code = 20003
+)
+
+// The basket api answers with these codes when a request refers to products
+// which are not in the catalog, the request is well formed but can not be
+// processed.
+func init() {
+	cerr.RegisterStatus(ProductNotFoundErrCode, http.StatusUnprocessableEntity)
+	cerr.RegisterStatus(SomeProductsNotFoundErrCode, http.StatusUnprocessableEntity)
+}
+
+func ProductNotFound() cerr.Bag {
+	return cerr.Bag{
+		Code:    ProductNotFoundErrCode,
+		Message: "Product not found.",
+	}
+}
+
+func SomeProductsNotFound() cerr.Bag {
+	return cerr.Bag{
+		Code:    SomeProductsNotFoundErrCode,
+		Message: "At least one of given product ids does not exist.",
+	}
+}
+
+// IsNotFound reports whether err is a not found answer of the product api
+// for a single product or for at least one product of a bulk request.
+func IsNotFound(err error) bool {
+	var bag cerr.Bag
+	if !errors.As(err, &bag) {
+		return false
+	}
+
+	return bag.Code == ProductNotFoundErrCode || bag.Code == SomeProductsNotFoundErrCode
+}
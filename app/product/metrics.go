@@ -0,0 +1,35 @@
+package product
+
+import (
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "product_cache",
+	Name:      "lookups_total",
+	Help:      "Lookups of the product cache by result, hit or miss.",
+}, []string{"result"})
+
+type cacheMetrics struct {
+	hits   prometheus.Counter
+	misses prometheus.Counter
+}
+
+// NewCacheMetrics exports the lookups of the caching client to prometheus.
+func NewCacheMetrics() CacheMetrics {
+	return &cacheMetrics{
+		hits:   cacheLookups.WithLabelValues("hit"),
+		misses: cacheLookups.WithLabelValues("miss"),
+	}
+}
+
+func (m *cacheMetrics) CacheHit() {
+	m.hits.Inc()
+}
+
+func (m *cacheMetrics) CacheMiss() {
+	m.misses.Inc()
+}
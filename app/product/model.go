@@ -18,10 +18,6 @@ type GetProductByIDsRequest struct {
 	IDs []string `json:"ids"`
 }
 
-type GetProductResponse struct {
-	Product Product `json:"product"`
-}
-
 type GetProductsResponse struct {
 	Products []Product `json:"products"`
 }
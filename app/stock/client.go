@@ -11,6 +11,10 @@ import (
 const (
 	isProductAvailableInStockPath = "%s/api/v1/stocks/availability"
 	reserveStockPath              = "%s/api/v1/stocks/reserve"
+	getStockByProductIDPath       = "%s/api/v1/stocks/%s"
+	releaseStockPath              = "%s/api/v1/stocks/release"
+	commitReservationPath         = "%s/api/v1/stocks/commit"
+	checkAvailabilityPath         = "%s/api/v1/stocks/availability/bulk"
 )
 
 type Client interface {
@@ -18,6 +22,14 @@ type Client interface {
 		ctx context.Context, req IsProductAvailableInStockRequest) (bool, error)
 	ReserveStock(
 		ctx context.Context, req ReserveStockRequest) (*Stock, error)
+	GetStockByProductID(
+		ctx context.Context, productID string) (*Stock, error)
+	ReleaseStock(
+		ctx context.Context, req ReleaseStockRequest) (*Stock, error)
+	CommitReservation(
+		ctx context.Context, req CommitReservationRequest) (*Stock, error)
+	CheckAvailability(
+		ctx context.Context, req CheckAvailabilityRequest) ([]ProductAvailability, error)
 }
 
 type client struct {
@@ -71,3 +83,71 @@ func (c *client) ReserveStock(
 
 	return &resp, nil
 }
+
+func (c *client) ReleaseStock(
+	ctx context.Context, req ReleaseStockRequest) (*Stock, error) {
+	url := fmt.Sprintf(releaseStockPath, c.baseURL)
+
+	body, err := c.httpClient.Put(ctx, url, c.headers, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp Stock
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *client) CommitReservation(
+	ctx context.Context, req CommitReservationRequest) (*Stock, error) {
+	url := fmt.Sprintf(commitReservationPath, c.baseURL)
+
+	body, err := c.httpClient.Put(ctx, url, c.headers, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp Stock
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *client) CheckAvailability(
+	ctx context.Context, req CheckAvailabilityRequest) ([]ProductAvailability, error) {
+	url := fmt.Sprintf(checkAvailabilityPath, c.baseURL)
+
+	body, err := c.httpClient.Post(ctx, url, c.headers, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp CheckAvailabilityResponse
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Products, nil
+}
+
+func (c *client) GetStockByProductID(
+	ctx context.Context, productID string) (*Stock, error) {
+	url := fmt.Sprintf(getStockByProductIDPath, c.baseURL, productID)
+
+	body, err := c.httpClient.Get(ctx, url, c.headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp Stock
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
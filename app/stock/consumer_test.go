@@ -25,8 +25,17 @@ const (
 
 const (
 	isProductAvailableInStockPath = "/api/v1/stocks/availability"
+	getStockByProductIDPath       = "/api/v1/stocks/%s"
+	reserveStockPath              = "/api/v1/stocks/reserve"
+	releaseStockPath              = "/api/v1/stocks/release"
+	commitReservationPath         = "/api/v1/stocks/commit"
+	checkAvailabilityPath         = "/api/v1/stocks/availability/bulk"
 )
 
+// uuidPattern matches the ids generated by the tests, the provider states
+// create stocks with their own ids.
+const uuidPattern = "[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}"
+
 type StockConsumerTestSuite struct {
 	suite.Suite
 	client        stock.Client
@@ -91,7 +100,7 @@ func (s *StockConsumerTestSuite) TestGivenStockInquiryForProductReqThenItShouldR
 
 	err := s.pact.Verify(test)
 
-	s.Equal(err, cerr.Bag{Code: 30000, Message: "Product id must be given to stock inquiry."})
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30000, Message: "Product id must be given to stock inquiry."}}, err)
 }
 
 func (s *StockConsumerTestSuite) TestGivenStockInquiryForProductReqThenItShouldReturnQuantityMustBeGivenErrWhenQuantityIsNotGiven() {
@@ -134,7 +143,7 @@ func (s *StockConsumerTestSuite) TestGivenStockInquiryForProductReqThenItShouldR
 
 	err := s.pact.Verify(test)
 
-	s.Equal(err, cerr.Bag{Code: 30002, Message: "Quantity must be given to stock inquiry."})
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30002, Message: "Quantity must be given to stock inquiry."}}, err)
 }
 
 func (s *StockConsumerTestSuite) TestGivenStockInquiryForProductReqThenItShouldReturnNoStockInfoFoundErrorWhenGivenProductIDNotHasStockInfo() {
@@ -179,7 +188,7 @@ func (s *StockConsumerTestSuite) TestGivenStockInquiryForProductReqThenItShouldR
 
 	err := s.pact.Verify(test)
 
-	s.Equal(err, cerr.Bag{Code: 30001, Message: "No stock information found for given product id."})
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30001, Message: "No stock information found for given product id."}}, err)
 }
 
 func (s *StockConsumerTestSuite) TestGivenStockInquiryForProductReqThenItShouldReturnFalseIfGivenProductIDNotInStockInGivenQuantity() {
@@ -268,6 +277,586 @@ func (s *StockConsumerTestSuite) TestGivenStockInquiryForProductReqThenItShouldR
 	s.Nil(err)
 }
 
+func (s *StockConsumerTestSuite) TestGivenGetStockByProductIDReqThenItShouldReturnStockWhenGivenProductIDHasStockInfo() {
+	givenProductID := gofakeit.UUID()
+
+	givenStock := stock.Stock{
+		ID:               gofakeit.UUID(),
+		ProductID:        givenProductID,
+		Quantity:         int(gofakeit.Uint8()),
+		ReservedQuantity: int(gofakeit.Uint8()),
+		CreatedAt:        gofakeit.Date(),
+		UpdatedAt:        gofakeit.Date(),
+	}
+
+	s.pact.
+		AddInteraction().
+		Given("i get stock information of given product").
+		UponReceiving("A request for stock information of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodGet,
+			Path: dsl.Term(
+				fmt.Sprintf(getStockByProductIDPath, givenProductID),
+				"^"+fmt.Sprintf(getStockByProductIDPath, uuidPattern)+"$",
+			),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusOK,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"id":                dsl.Like(givenStock.ID),
+				"product_id":        dsl.Like(givenStock.ProductID),
+				"quantity":          dsl.Like(givenStock.Quantity),
+				"reserved_quantity": dsl.Like(givenStock.ReservedQuantity),
+				"created_at":        dsl.Like(givenStock.CreatedAt),
+				"updated_at":        dsl.Like(givenStock.UpdatedAt),
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.GetStockByProductID(context.Background(), givenProductID)
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Nil(err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenReserveStockReqThenItShouldReturnStockWhenGivenQuantityIsReserved() {
+	givenStock := stock.Stock{
+		ID:               gofakeit.UUID(),
+		ProductID:        gofakeit.UUID(),
+		Quantity:         int(gofakeit.Uint8()),
+		ReservedQuantity: int(gofakeit.Uint8()),
+		CreatedAt:        gofakeit.Date(),
+		UpdatedAt:        gofakeit.Date(),
+	}
+	givenReservationID := gofakeit.UUID()
+	quantity := int(gofakeit.Uint8()) + 1
+
+	s.pact.
+		AddInteraction().
+		Given("i get stock information after given quantity is reserved").
+		UponReceiving("A request for reserving stock of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodPut,
+			Path:   dsl.String(reserveStockPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"reservation_id": dsl.Like(givenReservationID),
+				"product_id":     dsl.Like(givenStock.ProductID),
+				"quantity":       dsl.Like(quantity),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusOK,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"id":                dsl.Like(givenStock.ID),
+				"product_id":        dsl.Like(givenStock.ProductID),
+				"quantity":          dsl.Like(givenStock.Quantity),
+				"reserved_quantity": dsl.Like(givenStock.ReservedQuantity),
+				"created_at":        dsl.Like(givenStock.CreatedAt),
+				"updated_at":        dsl.Like(givenStock.UpdatedAt),
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.ReserveStock(context.Background(), stock.ReserveStockRequest{
+			ReservationID: givenReservationID,
+			ProductID:     givenStock.ProductID,
+			Quantity:      quantity,
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Nil(err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenReserveStockReqThenItShouldReturnNotEnoughStockErrWhenGivenQuantityIsNotAvailable() {
+	givenReservationID := gofakeit.UUID()
+	givenProductID := gofakeit.UUID()
+	quantity := int(gofakeit.Uint8()) + 1
+
+	s.pact.
+		AddInteraction().
+		Given("i get not enough stock error if given quantity is not available to reserve").
+		UponReceiving("A request for reserving stock of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodPut,
+			Path:   dsl.String(reserveStockPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"reservation_id": dsl.Like(givenReservationID),
+				"product_id":     dsl.Like(givenProductID),
+				"quantity":       dsl.Like(quantity),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusBadRequest,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"code":    30003,
+				"message": "Not enough stock to reserve for given product.",
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.ReserveStock(context.Background(), stock.ReserveStockRequest{
+			ReservationID: givenReservationID,
+			ProductID:     givenProductID,
+			Quantity:      quantity,
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30003, Message: "Not enough stock to reserve for given product."}}, err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenReserveStockReqThenItShouldReturnNoStockInfoFoundErrWhenGivenProductIDNotHasStockInfo() {
+	givenReservationID := gofakeit.UUID()
+	givenProductID := gofakeit.UUID()
+	quantity := int(gofakeit.Uint8()) + 1
+
+	s.pact.
+		AddInteraction().
+		Given("i get no stock information found error if no stock information found for given product id").
+		UponReceiving("A request for reserving stock of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodPut,
+			Path:   dsl.String(reserveStockPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"reservation_id": dsl.Like(givenReservationID),
+				"product_id":     dsl.Like(givenProductID),
+				"quantity":       dsl.Like(quantity),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusBadRequest,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"code":    30001,
+				"message": "No stock information found for given product id.",
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.ReserveStock(context.Background(), stock.ReserveStockRequest{
+			ReservationID: givenReservationID,
+			ProductID:     givenProductID,
+			Quantity:      quantity,
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30001, Message: "No stock information found for given product id."}}, err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenReserveStockReqThenItShouldReturnProductIDMustBeGivenErrWhenProductIDIsNotGiven() {
+	givenProductID := ""
+	quantity := int(gofakeit.Uint8()) + 1
+
+	s.pact.
+		AddInteraction().
+		Given("i get product id must be given error if product id is not given").
+		UponReceiving("A request for reserving stock of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodPut,
+			Path:   dsl.String(reserveStockPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"quantity": dsl.Like(quantity),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusBadRequest,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"code":    30000,
+				"message": "Product id must be given to stock inquiry.",
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.ReserveStock(context.Background(), stock.ReserveStockRequest{
+			ProductID: givenProductID,
+			Quantity:  quantity,
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30000, Message: "Product id must be given to stock inquiry."}}, err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenReserveStockReqThenItShouldReturnQuantityMustBeGivenErrWhenQuantityIsNotGiven() {
+	givenProductID := gofakeit.UUID()
+	quantity := 0
+
+	s.pact.
+		AddInteraction().
+		Given("i get quantity must be given error if quantity is not given").
+		UponReceiving("A request for reserving stock of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodPut,
+			Path:   dsl.String(reserveStockPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"product_id": dsl.Like(givenProductID),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusBadRequest,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"code":    30002,
+				"message": "Quantity must be given to stock inquiry.",
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.ReserveStock(context.Background(), stock.ReserveStockRequest{
+			ProductID: givenProductID,
+			Quantity:  quantity,
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30002, Message: "Quantity must be given to stock inquiry."}}, err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenReleaseStockReqThenItShouldReturnStockWhenReservedQuantityIsReleased() {
+	givenStock := stock.Stock{
+		ID:               gofakeit.UUID(),
+		ProductID:        gofakeit.UUID(),
+		Quantity:         int(gofakeit.Uint8()),
+		ReservedQuantity: int(gofakeit.Uint8()),
+		CreatedAt:        gofakeit.Date(),
+		UpdatedAt:        gofakeit.Date(),
+	}
+	quantity := int(gofakeit.Uint8()) + 1
+
+	s.pact.
+		AddInteraction().
+		Given("i get stock information after reserved quantity is released").
+		UponReceiving("A request for releasing reserved stock of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodPut,
+			Path:   dsl.String(releaseStockPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"stock_id":   dsl.Like(givenStock.ID),
+				"product_id": dsl.Like(givenStock.ProductID),
+				"quantity":   dsl.Like(quantity),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusOK,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"id":                dsl.Like(givenStock.ID),
+				"product_id":        dsl.Like(givenStock.ProductID),
+				"quantity":          dsl.Like(givenStock.Quantity),
+				"reserved_quantity": dsl.Like(givenStock.ReservedQuantity),
+				"created_at":        dsl.Like(givenStock.CreatedAt),
+				"updated_at":        dsl.Like(givenStock.UpdatedAt),
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.ReleaseStock(context.Background(), stock.ReleaseStockRequest{
+			StockID:   givenStock.ID,
+			ProductID: givenStock.ProductID,
+			Quantity:  quantity,
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Nil(err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenReleaseStockReqThenItShouldReturnNothingReservedErrWhenProductHasNoReservation() {
+	givenStockID := gofakeit.UUID()
+	givenProductID := gofakeit.UUID()
+	quantity := int(gofakeit.Uint8()) + 1
+
+	s.pact.
+		AddInteraction().
+		Given("i get nothing reserved error if there is no reserved stock to release for given product").
+		UponReceiving("A request for releasing reserved stock of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodPut,
+			Path:   dsl.String(releaseStockPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"stock_id":   dsl.Like(givenStockID),
+				"product_id": dsl.Like(givenProductID),
+				"quantity":   dsl.Like(quantity),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusBadRequest,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"code":    30004,
+				"message": "There is no reserved stock to release for given product.",
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.ReleaseStock(context.Background(), stock.ReleaseStockRequest{
+			StockID:   givenStockID,
+			ProductID: givenProductID,
+			Quantity:  quantity,
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30004, Message: "There is no reserved stock to release for given product."}}, err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenCommitReservationReqThenItShouldReturnStockWhenReservedQuantityIsCommitted() {
+	givenStock := stock.Stock{
+		ID:               gofakeit.UUID(),
+		ProductID:        gofakeit.UUID(),
+		Quantity:         int(gofakeit.Uint8()),
+		ReservedQuantity: int(gofakeit.Uint8()),
+		CreatedAt:        gofakeit.Date(),
+		UpdatedAt:        gofakeit.Date(),
+	}
+	quantity := int(gofakeit.Uint8()) + 1
+
+	s.pact.
+		AddInteraction().
+		Given("i get stock information after reserved quantity is committed").
+		UponReceiving("A request for committing reserved stock of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodPut,
+			Path:   dsl.String(commitReservationPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"stock_id":   dsl.Like(givenStock.ID),
+				"product_id": dsl.Like(givenStock.ProductID),
+				"quantity":   dsl.Like(quantity),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusOK,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"id":                dsl.Like(givenStock.ID),
+				"product_id":        dsl.Like(givenStock.ProductID),
+				"quantity":          dsl.Like(givenStock.Quantity),
+				"reserved_quantity": dsl.Like(givenStock.ReservedQuantity),
+				"created_at":        dsl.Like(givenStock.CreatedAt),
+				"updated_at":        dsl.Like(givenStock.UpdatedAt),
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.CommitReservation(context.Background(), stock.CommitReservationRequest{
+			StockID:   givenStock.ID,
+			ProductID: givenStock.ProductID,
+			Quantity:  quantity,
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Nil(err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenCommitReservationReqThenItShouldReturnNothingReservedErrWhenProductHasNoReservation() {
+	givenStockID := gofakeit.UUID()
+	givenProductID := gofakeit.UUID()
+	quantity := int(gofakeit.Uint8()) + 1
+
+	s.pact.
+		AddInteraction().
+		Given("i get nothing reserved error if there is no reserved stock to commit for given product").
+		UponReceiving("A request for committing reserved stock of a product").
+		WithRequest(dsl.Request{
+			Method: http.MethodPut,
+			Path:   dsl.String(commitReservationPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"stock_id":   dsl.Like(givenStockID),
+				"product_id": dsl.Like(givenProductID),
+				"quantity":   dsl.Like(quantity),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusBadRequest,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"code":    30005,
+				"message": "There is no reserved stock to commit for given product.",
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.CommitReservation(context.Background(), stock.CommitReservationRequest{
+			StockID:   givenStockID,
+			ProductID: givenProductID,
+			Quantity:  quantity,
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30005, Message: "There is no reserved stock to commit for given product."}}, err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenCheckAvailabilityReqThenItShouldReturnAvailabilityOfEachGivenProduct() {
+	givenProductID := gofakeit.UUID()
+	quantity := int(gofakeit.Uint8()) + 1
+
+	s.pact.
+		AddInteraction().
+		Given("i get availability of given products").
+		UponReceiving("A request for inquiry stock information about products").
+		WithRequest(dsl.Request{
+			Method: http.MethodPost,
+			Path:   dsl.String(checkAvailabilityPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"products": dsl.EachLike(dsl.StructMatcher{
+					"product_id": dsl.Like(givenProductID),
+					"quantity":   dsl.Like(quantity),
+				}, 1),
+			},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusOK,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"products": dsl.EachLike(dsl.StructMatcher{
+					"product_id":   dsl.Like(givenProductID),
+					"is_available": dsl.Like(true),
+				}, 1),
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.CheckAvailability(context.Background(), stock.CheckAvailabilityRequest{
+			Products: []stock.ProductQuantity{{ProductID: givenProductID, Quantity: quantity}},
+		})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Nil(err)
+}
+
+func (s *StockConsumerTestSuite) TestGivenCheckAvailabilityReqThenItShouldReturnProductsMustBeGivenErrWhenNoProductIsGiven() {
+	s.pact.
+		AddInteraction().
+		Given("i get products must be given error if no product is given").
+		UponReceiving("A request for inquiry stock information about products").
+		WithRequest(dsl.Request{
+			Method: http.MethodPost,
+			Path:   dsl.String(checkAvailabilityPath),
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+				fiber.HeaderAccept:      dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{},
+		}).
+		WillRespondWith(dsl.Response{
+			Status: http.StatusBadRequest,
+			Headers: map[string]dsl.Matcher{
+				fiber.HeaderContentType: dsl.String(fiber.MIMEApplicationJSON),
+			},
+			Body: dsl.StructMatcher{
+				"code":    30006,
+				"message": "Products must be given to stock inquiry.",
+			},
+		})
+
+	var test = func() error {
+		_, err := s.client.CheckAvailability(context.Background(), stock.CheckAvailabilityRequest{})
+		return err
+	}
+
+	err := s.pact.Verify(test)
+
+	s.Equal(&httpclient.ResponseError{StatusCode: http.StatusBadRequest, Bag: cerr.Bag{Code: 30006, Message: "Products must be given to stock inquiry."}}, err)
+}
+
 func (s *StockConsumerTestSuite) initPact() {
 	s.pact = &dsl.Pact{
 		Host:                     "127.0.0.1",
@@ -276,7 +865,7 @@ func (s *StockConsumerTestSuite) initPact() {
 		DisableToolValidityCheck: true,
 		PactFileWriteMode:        "overwrite",
 		LogDir:                   "./pacts/logs",
-		PactDir:                  ".././pacts",
+		PactDir:                  "./pacts",
 	}
 	//it must be used otherwise it could not create pact file
 	s.pact.Setup(true)
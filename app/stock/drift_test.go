@@ -0,0 +1,60 @@
+package stock_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/app/stock"
+	"github.com/pact-cdc-example/basket-service/pkg/pactdrift"
+	"github.com/pact-cdc-example/basket-service/pkg/pactstub"
+	"github.com/stretchr/testify/suite"
+)
+
+type StockContractDriftTestSuite struct {
+	suite.Suite
+}
+
+func TestStockContractDriftTestSuite(t *testing.T) {
+	suite.Run(t, new(StockContractDriftTestSuite))
+}
+
+func (s *StockContractDriftTestSuite) TestGivenCommittedPactsThenClientTypesShouldMatchThem() {
+	pacts, err := pactstub.Load("./pacts/*.json")
+	s.Require().Nil(err)
+	s.Require().NotEmpty(pacts)
+
+	mismatches := pactdrift.Check(pacts, []pactdrift.Contract{
+		{
+			Description: "A request for inquiry stock information about a product",
+			Request:     stock.IsProductAvailableInStockRequest{},
+			Response:    stock.IsProductAvailableInStockResponse{},
+		},
+		{
+			Description: "A request for inquiry stock information about products",
+			Request:     stock.CheckAvailabilityRequest{},
+			Response:    stock.CheckAvailabilityResponse{},
+		},
+		{
+			Description: "A request for stock information of a product",
+			Response:    stock.Stock{},
+		},
+		{
+			Description: "A request for reserving stock of a product",
+			Request:     stock.ReserveStockRequest{},
+			Response:    stock.Stock{},
+		},
+		{
+			Description: "A request for releasing reserved stock of a product",
+			Request:     stock.ReleaseStockRequest{},
+			Response:    stock.Stock{},
+		},
+		{
+			Description: "A request for committing reserved stock of a product",
+			Request:     stock.CommitReservationRequest{},
+			Response:    stock.Stock{},
+		},
+	})
+
+	for _, mismatch := range mismatches {
+		s.Fail(mismatch.String())
+	}
+}
@@ -6,6 +6,32 @@ type IsProductAvailableInStockRequest struct {
 }
 
 type ReserveStockRequest struct {
+	// ReservationID makes retried reservations idempotent, the stock
+	// service reserves once per reservation id.
+	ReservationID string `json:"reservation_id,omitempty"`
+	ProductID     string `json:"product_id,omitempty"`
+	Quantity      int    `json:"quantity,omitempty"`
+}
+
+type ReleaseStockRequest struct {
+	// StockID is the id the stock service answered the reservation with.
+	StockID   string `json:"stock_id,omitempty"`
+	ProductID string `json:"product_id,omitempty"`
+	Quantity  int    `json:"quantity,omitempty"`
+}
+
+type CommitReservationRequest struct {
+	// StockID is the id the stock service answered the reservation with.
+	StockID   string `json:"stock_id,omitempty"`
 	ProductID string `json:"product_id,omitempty"`
 	Quantity  int    `json:"quantity,omitempty"`
 }
+
+type CheckAvailabilityRequest struct {
+	Products []ProductQuantity `json:"products,omitempty"`
+}
+
+type ProductQuantity struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
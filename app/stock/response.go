@@ -14,3 +14,12 @@ type Stock struct {
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
+
+type CheckAvailabilityResponse struct {
+	Products []ProductAvailability `json:"products"`
+}
+
+type ProductAvailability struct {
+	ProductID   string `json:"product_id"`
+	IsAvailable bool   `json:"is_available"`
+}
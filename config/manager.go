@@ -7,6 +7,13 @@ type Manager interface {
 	ExternalURL() ExternalURL
 	Server() Server
 	Postgres() Postgres
+	ProductClient() ProductClient
+	Basket() Basket
+	Reservation() Reservation
+	Outbox() Outbox
+	Tracing() Tracing
+	Health() Health
+	Shutdown() Shutdown
 }
 
 type manager struct {
@@ -24,3 +31,31 @@ func (m *manager) Server() Server {
 func (m *manager) Postgres() Postgres {
 	return m.config.Postgres
 }
+
+func (m *manager) ProductClient() ProductClient {
+	return m.config.ProductClient
+}
+
+func (m *manager) Basket() Basket {
+	return m.config.Basket
+}
+
+func (m *manager) Reservation() Reservation {
+	return m.config.Reservation
+}
+
+func (m *manager) Outbox() Outbox {
+	return m.config.Outbox
+}
+
+func (m *manager) Tracing() Tracing {
+	return m.config.Tracing
+}
+
+func (m *manager) Health() Health {
+	return m.config.Health
+}
+
+func (m *manager) Shutdown() Shutdown {
+	return m.config.Shutdown
+}
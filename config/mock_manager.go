@@ -33,6 +33,20 @@ func (m *MockManager) EXPECT() *MockManagerMockRecorder {
 	return m.recorder
 }
 
+// Basket mocks base method.
+func (m *MockManager) Basket() Basket {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Basket")
+	ret0, _ := ret[0].(Basket)
+	return ret0
+}
+
+// Basket indicates an expected call of Basket.
+func (mr *MockManagerMockRecorder) Basket() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Basket", reflect.TypeOf((*MockManager)(nil).Basket))
+}
+
 // ExternalURL mocks base method.
 func (m *MockManager) ExternalURL() ExternalURL {
 	m.ctrl.T.Helper()
@@ -47,6 +61,34 @@ func (mr *MockManagerMockRecorder) ExternalURL() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExternalURL", reflect.TypeOf((*MockManager)(nil).ExternalURL))
 }
 
+// Health mocks base method.
+func (m *MockManager) Health() Health {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health")
+	ret0, _ := ret[0].(Health)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockManagerMockRecorder) Health() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockManager)(nil).Health))
+}
+
+// Outbox mocks base method.
+func (m *MockManager) Outbox() Outbox {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Outbox")
+	ret0, _ := ret[0].(Outbox)
+	return ret0
+}
+
+// Outbox indicates an expected call of Outbox.
+func (mr *MockManagerMockRecorder) Outbox() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outbox", reflect.TypeOf((*MockManager)(nil).Outbox))
+}
+
 // Postgres mocks base method.
 func (m *MockManager) Postgres() Postgres {
 	m.ctrl.T.Helper()
@@ -61,6 +103,34 @@ func (mr *MockManagerMockRecorder) Postgres() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Postgres", reflect.TypeOf((*MockManager)(nil).Postgres))
 }
 
+// ProductClient mocks base method.
+func (m *MockManager) ProductClient() ProductClient {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProductClient")
+	ret0, _ := ret[0].(ProductClient)
+	return ret0
+}
+
+// ProductClient indicates an expected call of ProductClient.
+func (mr *MockManagerMockRecorder) ProductClient() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProductClient", reflect.TypeOf((*MockManager)(nil).ProductClient))
+}
+
+// Reservation mocks base method.
+func (m *MockManager) Reservation() Reservation {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reservation")
+	ret0, _ := ret[0].(Reservation)
+	return ret0
+}
+
+// Reservation indicates an expected call of Reservation.
+func (mr *MockManagerMockRecorder) Reservation() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reservation", reflect.TypeOf((*MockManager)(nil).Reservation))
+}
+
 // Server mocks base method.
 func (m *MockManager) Server() Server {
 	m.ctrl.T.Helper()
@@ -74,3 +144,31 @@ func (mr *MockManagerMockRecorder) Server() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Server", reflect.TypeOf((*MockManager)(nil).Server))
 }
+
+// Shutdown mocks base method.
+func (m *MockManager) Shutdown() Shutdown {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown")
+	ret0, _ := ret[0].(Shutdown)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockManagerMockRecorder) Shutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockManager)(nil).Shutdown))
+}
+
+// Tracing mocks base method.
+func (m *MockManager) Tracing() Tracing {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tracing")
+	ret0, _ := ret[0].(Tracing)
+	return ret0
+}
+
+// Tracing indicates an expected call of Tracing.
+func (mr *MockManagerMockRecorder) Tracing() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tracing", reflect.TypeOf((*MockManager)(nil).Tracing))
+}
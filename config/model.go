@@ -1,9 +1,18 @@
 package config
 
+import "time"
+
 type config struct {
-	Postgres    Postgres    `mapstructure:"postgres"`
-	Server      Server      `mapstructure:"server"`
-	ExternalURL ExternalURL `mapstructure:"externalURL"`
+	Postgres      Postgres      `mapstructure:"postgres"`
+	Server        Server        `mapstructure:"server"`
+	ExternalURL   ExternalURL   `mapstructure:"externalURL"`
+	ProductClient ProductClient `mapstructure:"productClient"`
+	Basket        Basket        `mapstructure:"basket"`
+	Reservation   Reservation   `mapstructure:"reservation"`
+	Outbox        Outbox        `mapstructure:"outbox"`
+	Tracing       Tracing       `mapstructure:"tracing"`
+	Health        Health        `mapstructure:"health"`
+	Shutdown      Shutdown      `mapstructure:"shutdown"`
 }
 
 type Postgres struct {
@@ -22,3 +31,62 @@ type ExternalURL struct {
 	ProductAPI string `mapstructure:"productApi"`
 	StockAPI   string `mapstructure:"stockApi"`
 }
+
+type Basket struct {
+	PruneUnavailableProducts bool `mapstructure:"pruneUnavailableProducts"`
+}
+
+type Reservation struct {
+	TTL               time.Duration `mapstructure:"ttl"`
+	ExpiryInterval    time.Duration `mapstructure:"expiryInterval"`
+	ReconcileInterval time.Duration `mapstructure:"reconcileInterval"`
+}
+
+type Outbox struct {
+	PollInterval time.Duration `mapstructure:"pollInterval"`
+	BatchSize    int           `mapstructure:"batchSize"`
+	MaxAttempts  int           `mapstructure:"maxAttempts"`
+	BaseBackoff  time.Duration `mapstructure:"baseBackoff"`
+	MaxBackoff   time.Duration `mapstructure:"maxBackoff"`
+}
+
+type ProductClient struct {
+	Cache ProductCache `mapstructure:"cache"`
+	Batch ProductBatch `mapstructure:"batch"`
+}
+
+type ProductCache struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Size        int           `mapstructure:"size"`
+	TTL         time.Duration `mapstructure:"ttl"`
+	NegativeTTL time.Duration `mapstructure:"negativeTtl"`
+}
+
+type ProductBatch struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Window       time.Duration `mapstructure:"window"`
+	MaxBatchSize int           `mapstructure:"maxBatchSize"`
+}
+
+type Tracing struct {
+	// Exporter is one of otlp, stdout and none.
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRatio float64 `mapstructure:"sampleRatio"`
+}
+
+type Health struct {
+	CheckTimeout time.Duration `mapstructure:"checkTimeout"`
+	CacheTTL     time.Duration `mapstructure:"cacheTtl"`
+}
+
+type Shutdown struct {
+	// DrainPeriod is how long the service keeps serving after readiness is
+	// reported down, so load balancers stop routing to it first.
+	DrainPeriod     time.Duration `mapstructure:"drainPeriod"`
+	ServerTimeout   time.Duration `mapstructure:"serverTimeout"`
+	WorkersTimeout  time.Duration `mapstructure:"workersTimeout"`
+	DatabaseTimeout time.Duration `mapstructure:"databaseTimeout"`
+	TracingTimeout  time.Duration `mapstructure:"tracingTimeout"`
+}
@@ -1,20 +1,50 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/pact-cdc-example/basket-service/app/basket"
+	"github.com/pact-cdc-example/basket-service/app/basket/events"
 	"github.com/pact-cdc-example/basket-service/app/persistence"
 	"github.com/pact-cdc-example/basket-service/app/product"
 	"github.com/pact-cdc-example/basket-service/app/stock"
 	"github.com/pact-cdc-example/basket-service/config"
 	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
+	"github.com/pact-cdc-example/basket-service/pkg/lifecycle"
 	"github.com/pact-cdc-example/basket-service/pkg/postgres"
 	"github.com/pact-cdc-example/basket-service/pkg/server"
+	"github.com/pact-cdc-example/basket-service/pkg/tracing"
+	"github.com/pact-cdc-example/basket-service/pkg/worker"
 	"github.com/sirupsen/logrus"
 )
 
+const serviceName = "basket-service"
+
+// main runs the service, or the command named by the first argument. The
+// service takes its settings from the environment, so flags given to it are
+// ignored. Any other first argument has to name a command.
 func main() {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		switch os.Args[1] {
+		case "stubs":
+			if err := runStubs(config.New(), os.Args[2:]); err != nil {
+				log.Fatalf("stubs are stopped: %v", err)
+			}
+			return
+		case "pact":
+			if err := runPact(os.Args[2:]); err != nil {
+				log.Fatalf("pact command failed: %v", err)
+			}
+			return
+		default:
+			log.Fatalf("unknown command %q", os.Args[1])
+		}
+	}
+
 	c := config.New()
 
 	db := postgres.New(&postgres.NewPostgresOpts{
@@ -25,7 +55,23 @@ func main() {
 		Username: c.Postgres().Username,
 	})
 
+	if err := postgres.Migrate(context.Background(), db, persistence.Migrations()); err != nil {
+		log.Fatalf("could not migrate database: %v", err)
+	}
+
 	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	shutdownTracing, err := tracing.New(&tracing.NewOpts{
+		Exporter:    c.Tracing().Exporter,
+		Endpoint:    c.Tracing().Endpoint,
+		Insecure:    c.Tracing().Insecure,
+		ServiceName: serviceName,
+		SampleRatio: c.Tracing().SampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("could not set up tracing: %v", err)
+	}
 
 	repository := persistence.NewPostgresRepository(&persistence.NewPostgresRepositoryOpts{
 		DB: db,
@@ -39,6 +85,26 @@ func main() {
 		BaseURL:    c.ExternalURL().ProductAPI,
 	})
 
+	if batchConfig := c.ProductClient().Batch; batchConfig.Enabled {
+		productClient = product.NewBatchingClient(&product.NewBatchingClientOpts{
+			Client:       productClient,
+			Window:       batchConfig.Window,
+			MaxBatchSize: batchConfig.MaxBatchSize,
+		})
+	}
+
+	// the cache wraps the batcher, so hits are answered without waiting for
+	// the batch window and only the misses are batched.
+	if cacheConfig := c.ProductClient().Cache; cacheConfig.Enabled {
+		productClient = product.NewCachingClient(&product.NewCachingClientOpts{
+			Client:      productClient,
+			Size:        cacheConfig.Size,
+			TTL:         cacheConfig.TTL,
+			NegativeTTL: cacheConfig.NegativeTTL,
+			Metrics:     product.NewCacheMetrics(),
+		})
+	}
+
 	stockClient := stock.NewClient(&stock.NewClientOpts{
 		HTTPClient: httpClient,
 		BaseURL:    c.ExternalURL().StockAPI,
@@ -46,19 +112,123 @@ func main() {
 
 	basketService := basket.NewService(&basket.NewServiceOpts{
 		R: repository, L: logger, PC: productClient, SC: stockClient,
+		PruneUnavailableProducts: c.Basket().PruneUnavailableProducts,
+	})
+
+	basketService = basket.NewTracingService(&basket.NewTracingServiceOpts{
+		S: basketService,
 	})
 
 	basketHandler := basket.NewHandler(&basket.NewHandlerOpts{
 		S: basketService, L: logger,
 	})
 
+	// product and stock outages only degrade the service, taking every
+	// instance out of rotation would not bring them back.
+	health := server.NewHealth(&server.NewHealthOpts{
+		Checks: []server.Check{
+			{Checker: server.NewPingChecker("postgres", db), Critical: true},
+			{Checker: server.NewMigrationChecker(&server.NewMigrationCheckerOpts{
+				Name: "migrations", DB: db, Migrations: persistence.Migrations(),
+			}), Critical: true},
+			{Checker: server.NewHTTPChecker(&server.NewHTTPCheckerOpts{
+				Name: "product-api", URL: c.ExternalURL().ProductAPI,
+			})},
+			{Checker: server.NewHTTPChecker(&server.NewHTTPCheckerOpts{
+				Name: "stock-api", URL: c.ExternalURL().StockAPI,
+			})},
+		},
+		Timeout:  c.Health().CheckTimeout,
+		CacheTTL: c.Health().CacheTTL,
+		L:        logger,
+	})
+
 	app := server.New(&server.NewServerOpts{
-		Port: c.Server().Port,
+		Port:   c.Server().Port,
+		L:      logger,
+		Health: health,
 	}, []server.RouteHandler{
 		basketHandler,
 	})
 
-	if err := app.Run(); err != nil {
-		log.Fatalf("server is closed: %v", err)
+	reservationExpirer := basket.NewReservationExpirer(&basket.NewReservationExpirerOpts{
+		R: repository, L: logger, TTL: c.Reservation().TTL,
+	})
+
+	reservationReconciler := basket.NewReservationReconciler(&basket.NewReservationReconcilerOpts{
+		R: repository, L: logger, SC: stockClient,
+	})
+
+	outboxRelay := basket.NewOutboxRelay(&basket.NewOutboxRelayOpts{
+		R:           repository,
+		L:           logger,
+		SC:          stockClient,
+		EP:          events.NewLogPublisher(&events.NewLogPublisherOpts{L: logger}),
+		BatchSize:   c.Outbox().BatchSize,
+		MaxAttempts: c.Outbox().MaxAttempts,
+		BaseBackoff: c.Outbox().BaseBackoff,
+		MaxBackoff:  c.Outbox().MaxBackoff,
+	})
+
+	workers := worker.NewGroup(
+		worker.NewPeriodic(&worker.NewPeriodicOpts{
+			Name:     "outbox-relay",
+			Interval: c.Outbox().PollInterval,
+			Job:      outboxRelay.Relay,
+			L:        logger,
+		}),
+		worker.NewPeriodic(&worker.NewPeriodicOpts{
+			Name:     "reservation-expirer",
+			Interval: c.Reservation().ExpiryInterval,
+			Job:      reservationExpirer.ExpireReservations,
+			L:        logger,
+		}),
+		worker.NewPeriodic(&worker.NewPeriodicOpts{
+			Name:     "reservation-reconciler",
+			Interval: c.Reservation().ReconcileInterval,
+			Job: func(ctx context.Context) error {
+				_, err := reservationReconciler.Reconcile(ctx)
+				return err
+			},
+			L: logger,
+		}),
+	)
+
+	shutdownConfig := c.Shutdown()
+
+	// readiness goes down first and the service keeps serving for the drain
+	// period, so load balancers stop routing to it before it stops listening.
+	// The database is closed last, the requests and jobs in flight need it.
+	lifecycleManager := lifecycle.NewManager(&lifecycle.NewManagerOpts{
+		Steps: []lifecycle.Step{
+			{Name: "readiness", Stop: func(context.Context) error {
+				health.Drain()
+				return nil
+			}},
+			// the drain only waits, its timeout must not cut it short
+			{Name: "drain", Stop: lifecycle.Delay(shutdownConfig.DrainPeriod),
+				Timeout: shutdownConfig.DrainPeriod + time.Second},
+			{Name: "server", Stop: app.Shutdown, Timeout: shutdownConfig.ServerTimeout},
+			{Name: "workers", Stop: workers.Stop, Timeout: shutdownConfig.WorkersTimeout},
+			{Name: "database", Stop: func(context.Context) error {
+				return db.Close()
+			}, Timeout: shutdownConfig.DatabaseTimeout},
+			{Name: "tracing", Stop: shutdownTracing, Timeout: shutdownConfig.TracingTimeout},
+		},
+		L: logger,
+	})
+
+	workers.Start()
+
+	// a server which fails to listen has served no request, there is
+	// nothing to drain so the process exits right away with the error.
+	go func() {
+		if err := app.Run(); err != nil {
+			log.Fatalf("server is closed: %v", err)
+		}
+	}()
+
+	if err := lifecycleManager.Run(context.Background()); err != nil {
+		log.Fatalf("could not shut down gracefully: %v", err)
 	}
 }
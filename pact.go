@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/pkg/broker"
+)
+
+const pacticipantBasketService = "BasketService"
+
+// runPact publishes the pacts of this service, tags its versions and asks the
+// broker whether a version can be deployed.
+func runPact(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: basket-service pact publish|tag|can-i-deploy [flags]")
+	}
+
+	flags := flag.NewFlagSet("pact "+args[0], flag.ExitOnError)
+	brokerURL := flags.String("broker-url", envOr("BROKER_URL", "http://localhost"), "pact broker url")
+	brokerToken := flags.String("broker-token", os.Getenv("BROKER_TOKEN"), "pact broker bearer token")
+	brokerUsername := flags.String("broker-username", os.Getenv("BROKER_USERNAME"), "pact broker username")
+	brokerPassword := flags.String("broker-password", os.Getenv("BROKER_PASSWORD"), "pact broker password")
+	pacticipant := flags.String("pacticipant", pacticipantBasketService, "name of this service in the broker")
+	version := flags.String("version", "", "version to publish, CONSUMER_VERSION or the git commit by default")
+	branch := flags.String("branch", "", "branch of the version, BRANCH_NAME or the git branch by default")
+	pactsDir := flags.String("pacts-dir", "app", "directory the pacts are discovered in")
+	to := flags.String("to", "", "tag of the versions to check deployment with")
+	environment := flags.String("environment", "", "environment to check deployment to")
+	retries := flags.Int("retry-while-unknown", 12, "times to ask again while verification results are unknown")
+	retryInterval := flags.Duration("retry-interval", 10*time.Second, "time to wait before asking again")
+	var tags stringsFlag
+	flags.Var(&tags, "tag", "tag of the version, can be repeated")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	revision, err := broker.ResolveRevision(broker.Revision{Version: *version, Branch: *branch})
+	if err != nil {
+		return fmt.Errorf("could not find version: %w", err)
+	}
+
+	client := broker.NewClient(&broker.NewClientOpts{
+		BaseURL:  *brokerURL,
+		Token:    *brokerToken,
+		Username: *brokerUsername,
+		Password: *brokerPassword,
+	})
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "publish":
+		pacts, err := broker.DiscoverPacts(*pactsDir, *pacticipant)
+		if err != nil {
+			return err
+		}
+		if len(pacts) == 0 {
+			return fmt.Errorf("no pact of %s found in %s", *pacticipant, *pactsDir)
+		}
+
+		if err = broker.Publish(ctx, client, pacts, revision, tags); err != nil {
+			return err
+		}
+
+		for _, pact := range pacts {
+			fmt.Fprintf(os.Stderr, "published %s as %s %s\n", pact.Path, pact.Consumer, revision.Version)
+		}
+		return nil
+	case "tag":
+		if len(tags) == 0 {
+			return errors.New("at least one tag must be given")
+		}
+
+		for _, tag := range tags {
+			if err := client.CreateVersionTag(ctx, *pacticipant, revision.Version, tag); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "tagged %s %s with %s\n", *pacticipant, revision.Version, tag)
+		}
+		return nil
+	case "can-i-deploy":
+		if *to == "" && *environment == "" {
+			return errors.New("one of to or environment must be given")
+		}
+
+		result, err := broker.WaitCanIDeploy(ctx, client, broker.CanIDeployRequest{
+			Pacticipant: *pacticipant,
+			Version:     revision.Version,
+			To:          *to,
+			Environment: *environment,
+		}, *retries, *retryInterval)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "%s %s: %s\n", *pacticipant, revision.Version, result.Summary.Reason)
+		if !result.Deployable() {
+			return errors.New("version can not be deployed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown pact command %q", args[0])
+	}
+}
+
+func envOr(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
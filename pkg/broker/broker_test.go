@@ -0,0 +1,160 @@
+package broker_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/pkg/broker"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeBroker records the requests it receives and answers can-i-deploy with
+// the summaries it is given, one per request.
+type fakeBroker struct {
+	mu         sync.Mutex
+	requests   []string
+	bodies     map[string]string
+	auth       string
+	summaries  []string
+	canIDeploy int
+}
+
+func (f *fakeBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.RequestURI())
+	f.bodies[r.URL.Path] = string(body)
+	f.auth = r.Header.Get("Authorization")
+
+	if r.URL.Path == "/can-i-deploy" {
+		summary := f.summaries[f.canIDeploy]
+		f.canIDeploy++
+		_, _ = w.Write([]byte(`{"summary":` + summary + `}`))
+		return
+	}
+
+	if r.URL.Path == "/pacts/provider/Unknown/consumer/BasketService/version/1.0.0" {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+type BrokerTestSuite struct {
+	suite.Suite
+	fake   *fakeBroker
+	server *httptest.Server
+	client broker.Client
+}
+
+func TestBrokerTestSuite(t *testing.T) {
+	suite.Run(t, new(BrokerTestSuite))
+}
+
+func (s *BrokerTestSuite) SetupTest() {
+	s.fake = &fakeBroker{bodies: map[string]string{}}
+	s.server = httptest.NewServer(s.fake)
+	s.client = broker.NewClient(&broker.NewClientOpts{
+		BaseURL: s.server.URL,
+		Token:   "secret",
+	})
+}
+
+func (s *BrokerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *BrokerTestSuite) TestGivenPactsThenItShouldPublishBranchAndTagTheVersion() {
+	pacts := []broker.PactFile{
+		{Consumer: "BasketService", Provider: "StockService", Content: []byte(`{"a":1}`)},
+		{Consumer: "BasketService", Provider: "ProductService", Content: []byte(`{"b":2}`)},
+	}
+
+	err := broker.Publish(context.Background(), s.client, pacts,
+		broker.Revision{Version: "1.0.0", Branch: "main"}, []string{"dev"})
+
+	s.Nil(err)
+	s.Equal([]string{
+		"PUT /pacts/provider/StockService/consumer/BasketService/version/1.0.0",
+		"PUT /pacts/provider/ProductService/consumer/BasketService/version/1.0.0",
+		"PUT /pacticipants/BasketService/branches/main/versions/1.0.0",
+		"PUT /pacticipants/BasketService/versions/1.0.0/tags/dev",
+	}, s.fake.requests)
+	s.Equal(`{"a":1}`, s.fake.bodies["/pacts/provider/StockService/consumer/BasketService/version/1.0.0"])
+	s.Equal("Bearer secret", s.fake.auth)
+}
+
+func (s *BrokerTestSuite) TestGivenBrokerRejectsPactThenItShouldFail() {
+	pacts := []broker.PactFile{{Path: "unknown.json", Consumer: "BasketService", Provider: "Unknown"}}
+
+	err := broker.Publish(context.Background(), s.client, pacts, broker.Revision{Version: "1.0.0"}, nil)
+
+	var brokerErr *broker.Error
+	s.ErrorAs(err, &brokerErr)
+	s.Equal(http.StatusUnprocessableEntity, brokerErr.StatusCode)
+}
+
+func (s *BrokerTestSuite) TestGivenUnknownVerificationThenItShouldAskAgain() {
+	s.fake.summaries = []string{
+		`{"deployable":null,"reason":"verification pending","unknown":1}`,
+		`{"deployable":true,"reason":"all verified","success":2}`,
+	}
+
+	result, err := broker.WaitCanIDeploy(context.Background(), s.client, broker.CanIDeployRequest{
+		Pacticipant: "BasketService", Version: "1.0.0", To: "dev",
+	}, 3, 0)
+
+	s.Nil(err)
+	s.True(result.Deployable())
+	s.Equal([]string{
+		"GET /can-i-deploy?pacticipant=BasketService&to=dev&version=1.0.0",
+		"GET /can-i-deploy?pacticipant=BasketService&to=dev&version=1.0.0",
+	}, s.fake.requests)
+}
+
+func (s *BrokerTestSuite) TestGivenRetriesAreUsedUpThenItShouldReturnUnknownResult() {
+	s.fake.summaries = []string{`{"deployable":null}`, `{"deployable":null}`}
+
+	result, err := broker.WaitCanIDeploy(context.Background(), s.client, broker.CanIDeployRequest{
+		Pacticipant: "BasketService", Version: "1.0.0", Environment: "production",
+	}, 1, 0)
+
+	s.Nil(err)
+	s.True(result.Unknown())
+	s.False(result.Deployable())
+}
+
+func (s *BrokerTestSuite) TestGivenPactDirectoriesThenItShouldDiscoverPactsOfConsumer() {
+	root := s.T().TempDir()
+	s.writePact(filepath.Join(root, "stock", "pacts", "basketservice-stockservice.json"), "BasketService", "StockService")
+	s.writePact(filepath.Join(root, "basket", "testdata", "pacts", "frontend-basketservice.json"), "Frontend", "BasketService")
+	s.writePact(filepath.Join(root, "stock", "fixtures", "basketservice-stockservice.json"), "BasketService", "StockService")
+
+	pacts, err := broker.DiscoverPacts(root, "BasketService")
+
+	s.Nil(err)
+	s.Len(pacts, 1)
+	s.Equal("StockService", pacts[0].Provider)
+}
+
+func (s *BrokerTestSuite) writePact(path string, consumer string, provider string) {
+	s.Require().Nil(os.MkdirAll(filepath.Dir(path), 0o755))
+
+	content, err := json.Marshal(map[string]interface{}{
+		"consumer": map[string]string{"name": consumer},
+		"provider": map[string]string{"name": provider},
+	})
+	s.Require().Nil(err)
+	s.Require().Nil(os.WriteFile(path, content, 0o644))
+}
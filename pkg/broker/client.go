@@ -0,0 +1,194 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	publishPactPath         = "%s/pacts/provider/%s/consumer/%s/version/%s"
+	createVersionTagPath    = "%s/pacticipants/%s/versions/%s/tags/%s"
+	createBranchVersionPath = "%s/pacticipants/%s/branches/%s/versions/%s"
+	canIDeployPath          = "%s/can-i-deploy"
+)
+
+type Client interface {
+	PublishPact(ctx context.Context, pact PactFile, version string) error
+	CreateVersionTag(ctx context.Context, pacticipant string, version string, tag string) error
+	CreateBranchVersion(ctx context.Context, pacticipant string, branch string, version string) error
+	CanIDeploy(ctx context.Context, req CanIDeployRequest) (*CanIDeployResult, error)
+}
+
+type client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+	username   string
+	password   string
+}
+
+type NewClientOpts struct {
+	BaseURL string
+	// Token is sent as a bearer token, Username and Password are used for
+	// basic authentication when no token is given.
+	Token    string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+func NewClient(opts *NewClientOpts) Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &client{
+		httpClient: &http.Client{Timeout: timeout},
+		baseURL:    opts.BaseURL,
+		token:      opts.Token,
+		username:   opts.Username,
+		password:   opts.Password,
+	}
+}
+
+func (c *client) PublishPact(ctx context.Context, pact PactFile, version string) error {
+	u := fmt.Sprintf(publishPactPath, c.baseURL,
+		url.PathEscape(pact.Provider), url.PathEscape(pact.Consumer), url.PathEscape(version))
+
+	_, err := c.do(ctx, http.MethodPut, u, pact.Content)
+	return err
+}
+
+func (c *client) CreateVersionTag(
+	ctx context.Context, pacticipant string, version string, tag string) error {
+	u := fmt.Sprintf(createVersionTagPath, c.baseURL,
+		url.PathEscape(pacticipant), url.PathEscape(version), url.PathEscape(tag))
+
+	_, err := c.do(ctx, http.MethodPut, u, []byte("{}"))
+	return err
+}
+
+func (c *client) CreateBranchVersion(
+	ctx context.Context, pacticipant string, branch string, version string) error {
+	u := fmt.Sprintf(createBranchVersionPath, c.baseURL,
+		url.PathEscape(pacticipant), url.PathEscape(branch), url.PathEscape(version))
+
+	_, err := c.do(ctx, http.MethodPut, u, []byte("{}"))
+	return err
+}
+
+type CanIDeployRequest struct {
+	Pacticipant string
+	Version     string
+	// To is the tag of the versions the pacticipant is deployed with, it is
+	// ignored when Environment is given.
+	To          string
+	Environment string
+}
+
+type CanIDeployResult struct {
+	Summary CanIDeploySummary `json:"summary"`
+}
+
+// CanIDeploySummary tells whether the version is verified against the
+// versions it is deployed with, Deployable is nil while a verification
+// result is unknown.
+type CanIDeploySummary struct {
+	Deployable *bool  `json:"deployable"`
+	Reason     string `json:"reason"`
+	Success    int    `json:"success"`
+	Failed     int    `json:"failed"`
+	Unknown    int    `json:"unknown"`
+}
+
+func (r *CanIDeployResult) Deployable() bool {
+	return r.Summary.Deployable != nil && *r.Summary.Deployable
+}
+
+func (r *CanIDeployResult) Unknown() bool {
+	return r.Summary.Deployable == nil
+}
+
+func (c *client) CanIDeploy(ctx context.Context, req CanIDeployRequest) (*CanIDeployResult, error) {
+	query := url.Values{}
+	query.Set("pacticipant", req.Pacticipant)
+	query.Set("version", req.Version)
+	if req.Environment != "" {
+		query.Set("environment", req.Environment)
+	} else {
+		query.Set("to", req.To)
+	}
+
+	u := fmt.Sprintf(canIDeployPath, c.baseURL) + "?" + query.Encode()
+
+	body, err := c.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result CanIDeployResult
+	if err = json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Error is returned when the broker answers with an unsuccessful status.
+type Error struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("broker answered %s %s with %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+func (c *client) do(ctx context.Context, method string, u string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/hal+json, application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return nil, &Error{Method: method, URL: u, StatusCode: res.StatusCode, Body: string(content)}
+	}
+
+	return content, nil
+}
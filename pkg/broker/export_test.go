@@ -0,0 +1,4 @@
+package broker
+
+// ResolveRevisionOf resolves the revision with the given environment and git.
+var ResolveRevisionOf = resolveRevision
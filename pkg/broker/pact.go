@@ -0,0 +1,66 @@
+package broker
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// PactFile is a pact file found on disk together with its pacticipants.
+type PactFile struct {
+	Path     string
+	Consumer string
+	Provider string
+	Content  []byte
+}
+
+type pactPacticipants struct {
+	Consumer struct {
+		Name string `json:"name"`
+	} `json:"consumer"`
+	Provider struct {
+		Name string `json:"name"`
+	} `json:"provider"`
+}
+
+// DiscoverPacts returns the pact files of consumer in the pacts directories
+// under root. Pacts of other consumers, such as the ones kept to verify this
+// service as a provider, are skipped.
+func DiscoverPacts(root string, consumer string) ([]PactFile, error) {
+	var pacts []PactFile
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(path) != ".json" || filepath.Base(filepath.Dir(path)) != "pacts" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var pacticipants pactPacticipants
+		if err = json.Unmarshal(content, &pacticipants); err != nil {
+			return err
+		}
+
+		if pacticipants.Consumer.Name != consumer {
+			return nil
+		}
+
+		pacts = append(pacts, PactFile{
+			Path:     path,
+			Consumer: pacticipants.Consumer.Name,
+			Provider: pacticipants.Provider.Name,
+			Content:  content,
+		})
+
+		return nil
+	})
+
+	return pacts, err
+}
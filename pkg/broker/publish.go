@@ -0,0 +1,57 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Publish publishes the pacts with the revision version, records the version
+// on the revision branch and tags it with tags.
+func Publish(ctx context.Context, client Client, pacts []PactFile, revision Revision, tags []string) error {
+	pacticipants := make(map[string]bool)
+	for _, pact := range pacts {
+		if err := client.PublishPact(ctx, pact, revision.Version); err != nil {
+			return fmt.Errorf("could not publish %s: %w", pact.Path, err)
+		}
+		pacticipants[pact.Consumer] = true
+	}
+
+	for pacticipant := range pacticipants {
+		if revision.Branch != "" {
+			if err := client.CreateBranchVersion(ctx, pacticipant, revision.Branch, revision.Version); err != nil {
+				return fmt.Errorf("could not record branch %s: %w", revision.Branch, err)
+			}
+		}
+
+		for _, tag := range tags {
+			if err := client.CreateVersionTag(ctx, pacticipant, revision.Version, tag); err != nil {
+				return fmt.Errorf("could not tag version with %s: %w", tag, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// WaitCanIDeploy asks the broker whether the version can be deployed and asks
+// again, up to retries times, while a verification result is unknown.
+func WaitCanIDeploy(
+	ctx context.Context, client Client, req CanIDeployRequest, retries int, interval time.Duration) (*CanIDeployResult, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := client.CanIDeploy(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if !result.Unknown() || attempt >= retries {
+			return result, nil
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
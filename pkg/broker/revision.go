@@ -0,0 +1,68 @@
+package broker
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	versionEnv = "CONSUMER_VERSION"
+	branchEnv  = "BRANCH_NAME"
+)
+
+// Revision is the version and branch a pacticipant is published with.
+type Revision struct {
+	Version string
+	Branch  string
+}
+
+// ResolveRevision fills the empty fields of given one by one, from the
+// environment the way the build sets them and then from the commit and branch
+// git checks out. The branch is left empty when it can not be found, and a
+// given version is kept when git is not available.
+func ResolveRevision(given Revision) (Revision, error) {
+	return resolveRevision(given, os.Getenv, git)
+}
+
+func resolveRevision(
+	revision Revision, getenv func(string) string, git func(args ...string) (string, error)) (Revision, error) {
+	if revision.Version == "" {
+		revision.Version = getenv(versionEnv)
+	}
+	if revision.Branch == "" {
+		revision.Branch = getenv(branchEnv)
+	}
+
+	// the branch is optional, a pact is published without one when git can
+	// not tell it, such as in a detached checkout.
+	if revision.Branch == "" {
+		if branch, err := git("rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+			revision.Branch = branch
+		}
+	}
+
+	if revision.Version == "" {
+		version, err := git("rev-parse", "--short", "HEAD")
+		if err != nil {
+			return Revision{}, err
+		}
+		revision.Version = version
+	}
+
+	if revision.Version == "" {
+		return Revision{}, errors.New("version could not be found")
+	}
+
+	return revision, nil
+}
+
+func git(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
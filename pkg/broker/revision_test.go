@@ -0,0 +1,92 @@
+package broker_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/pkg/broker"
+	"github.com/stretchr/testify/suite"
+)
+
+type RevisionTestSuite struct {
+	suite.Suite
+}
+
+func TestRevisionTestSuite(t *testing.T) {
+	suite.Run(t, new(RevisionTestSuite))
+}
+
+func (s *RevisionTestSuite) TestGivenVersionSetAndBranchLookupFailingThenItShouldBeReadWithoutBranch() {
+	revision, err := broker.ResolveRevisionOf(broker.Revision{}, env(map[string]string{"CONSUMER_VERSION": "1.0.0"}),
+		func(args ...string) (string, error) {
+			return "", errors.New("not a git repository")
+		})
+
+	s.Nil(err)
+	s.Equal(broker.Revision{Version: "1.0.0"}, revision)
+}
+
+func (s *RevisionTestSuite) TestGivenDetachedHeadThenItShouldBeReadWithoutBranch() {
+	revision, err := broker.ResolveRevisionOf(broker.Revision{}, env(nil), fakeGit("HEAD"))
+
+	s.Nil(err)
+	s.Equal(broker.Revision{Version: "abc1234"}, revision)
+}
+
+func (s *RevisionTestSuite) TestGivenBranchCheckedOutThenItShouldBeRead() {
+	revision, err := broker.ResolveRevisionOf(broker.Revision{}, env(nil), fakeGit("main"))
+
+	s.Nil(err)
+	s.Equal(broker.Revision{Version: "abc1234", Branch: "main"}, revision)
+}
+
+func (s *RevisionTestSuite) TestGivenBranchSetThenGitBranchShouldNotBeUsed() {
+	revision, err := broker.ResolveRevisionOf(broker.Revision{}, env(map[string]string{"BRANCH_NAME": "feature"}), fakeGit("main"))
+
+	s.Nil(err)
+	s.Equal(broker.Revision{Version: "abc1234", Branch: "feature"}, revision)
+}
+
+func (s *RevisionTestSuite) TestGivenNoVersionThenItShouldFail() {
+	_, err := broker.ResolveRevisionOf(broker.Revision{}, env(nil), func(args ...string) (string, error) {
+		return "", errors.New("not a git repository")
+	})
+
+	s.NotNil(err)
+}
+
+func (s *RevisionTestSuite) TestGivenVersionAndNoGitThenBranchShouldStillBeReadFromEnvironment() {
+	revision, err := broker.ResolveRevisionOf(broker.Revision{Version: "2.0.0"},
+		env(map[string]string{"BRANCH_NAME": "feature"}),
+		func(args ...string) (string, error) {
+			return "", errors.New("not a git repository")
+		})
+
+	s.Nil(err)
+	s.Equal(broker.Revision{Version: "2.0.0", Branch: "feature"}, revision)
+}
+
+func (s *RevisionTestSuite) TestGivenBranchThenItShouldWinOverEnvironmentAndGit() {
+	revision, err := broker.ResolveRevisionOf(broker.Revision{Branch: "release"},
+		env(map[string]string{"BRANCH_NAME": "feature"}), fakeGit("main"))
+
+	s.Nil(err)
+	s.Equal(broker.Revision{Version: "abc1234", Branch: "release"}, revision)
+}
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+// fakeGit answers the commit as abc1234 and the branch as branch.
+func fakeGit(branch string) func(args ...string) (string, error) {
+	return func(args ...string) (string, error) {
+		if args[len(args)-2] == "--abbrev-ref" {
+			return branch, nil
+		}
+
+		return "abc1234", nil
+	}
+}
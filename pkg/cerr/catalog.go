@@ -0,0 +1,115 @@
+package cerr
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the locale of the messages the bags are created with,
+// the catalog only holds the translations to the other locales.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+type translations struct {
+	Messages map[string]string `json:"messages"`
+	// Rules are the templates of the validation details, {field} and the
+	// parameters of the rule such as {min} are replaced.
+	Rules map[string]string `json:"rules"`
+}
+
+type catalog struct {
+	locales      []string
+	matcher      language.Matcher
+	translations map[string]translations
+}
+
+var messages = mustLoadCatalog()
+
+func mustLoadCatalog() *catalog {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	c := &catalog{
+		locales:      []string{DefaultLocale},
+		translations: make(map[string]translations, len(files)),
+	}
+	tags := []language.Tag{language.MustParse(DefaultLocale)}
+
+	for _, file := range files {
+		content, err := localeFiles.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		var t translations
+		if err = json.Unmarshal(content, &t); err != nil {
+			panic("could not parse locale file " + file.Name() + ": " + err.Error())
+		}
+
+		locale := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+		c.locales = append(c.locales, locale)
+		c.translations[locale] = t
+		tags = append(tags, language.MustParse(locale))
+	}
+
+	c.matcher = language.NewMatcher(tags)
+
+	return c
+}
+
+// Locales returns the locales messages are available in.
+func Locales() []string {
+	return append([]string(nil), messages.locales...)
+}
+
+// NegotiateLocale picks the available locale which suits the languages of
+// an Accept-Language header best, the default locale when none does.
+func NegotiateLocale(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+
+	_, index, confidence := messages.matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+
+	return messages.locales[index]
+}
+
+// Localize returns a copy of the bag with its message and details in
+// locale. Codes without a translation keep the message they were created
+// with, the code itself never changes.
+func Localize(b Bag, locale string) Bag {
+	t, ok := messages.translations[locale]
+	if !ok {
+		return b
+	}
+
+	if message, ok := t.Messages[strconv.Itoa(int(b.Code))]; ok {
+		b.Message = message
+	}
+
+	if len(b.Details) > 0 {
+		details := make([]Detail, len(b.Details))
+		for i, detail := range b.Details {
+			if template, ok := t.Rules[detail.Rule]; ok {
+				detail.Message = detail.render(template)
+			}
+			details[i] = detail
+		}
+		b.Details = details
+	}
+
+	return b
+}
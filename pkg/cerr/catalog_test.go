@@ -0,0 +1,56 @@
+package cerr_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/stretchr/testify/suite"
+)
+
+type CatalogTestSuite struct {
+	suite.Suite
+}
+
+func TestCatalogTestSuite(t *testing.T) {
+	suite.Run(t, new(CatalogTestSuite))
+}
+
+func (s *CatalogTestSuite) TestGivenAcceptLanguageThenItShouldNegotiateTheBestLocale() {
+	s.Equal("de", cerr.NegotiateLocale("de-DE,de;q=0.9,en;q=0.8"))
+	s.Equal("tr", cerr.NegotiateLocale("fr;q=0.9, tr;q=0.8"))
+	s.Equal("en", cerr.NegotiateLocale("en-GB"))
+	s.Equal(cerr.DefaultLocale, cerr.NegotiateLocale("ja"))
+	s.Equal(cerr.DefaultLocale, cerr.NegotiateLocale(""))
+	s.Equal(cerr.DefaultLocale, cerr.NegotiateLocale("not a;;header"))
+}
+
+func (s *CatalogTestSuite) TestGivenTranslatedCodeThenOnlyTheMessageShouldChange() {
+	bag := cerr.Localize(cerr.Processing(), "de")
+
+	s.Equal(cerr.ProcessingErrCode, bag.Code)
+	s.Equal("Bei der Verarbeitung der Anfrage ist ein Fehler aufgetreten.", bag.Message)
+}
+
+func (s *CatalogTestSuite) TestGivenDefaultLocaleOrUntranslatedCodeThenTheMessageShouldBeKept() {
+	s.Equal(cerr.Processing(), cerr.Localize(cerr.Processing(), cerr.DefaultLocale))
+
+	untranslated := cerr.Bag{Code: 99201, Message: "untranslated"}
+	s.Equal(untranslated, cerr.Localize(untranslated, "tr"))
+}
+
+func (s *CatalogTestSuite) TestGivenValidationDetailsThenTheirMessagesShouldBeTranslated() {
+	var v cerr.Validator
+	v.Required("user_id", "")
+	v.Min("quantity", 0, 1)
+	original := v.Err().(cerr.Bag)
+
+	bag := cerr.Localize(original, "de")
+
+	s.Equal("user_id muss angegeben werden.", bag.Details[0].Message)
+	s.Equal("quantity muss mindestens 1 sein.", bag.Details[1].Message)
+	s.Equal("quantity must be at least 1.", original.Details[1].Message)
+}
+
+func (s *CatalogTestSuite) TestGivenEmbeddedFilesThenEveryLocaleShouldBeAvailable() {
+	s.ElementsMatch([]string{"en", "de", "tr"}, cerr.Locales())
+}
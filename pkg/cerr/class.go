@@ -0,0 +1,97 @@
+package cerr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Class tells how a failure should be handled, whether retrying it can help
+// and whom to blame for it.
+type Class int
+
+const (
+	// ClassPermanent failures are answered the same way again, such as bugs
+	// or corrupt data.
+	ClassPermanent Class = iota
+	// ClassTransient failures may succeed when retried later.
+	ClassTransient
+	// ClassClient failures are caused by the request itself.
+	ClassClient
+	// ClassDependency failures are caused by a service the request needs.
+	ClassDependency
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassTransient:
+		return "transient"
+	case ClassClient:
+		return "client"
+	case ClassDependency:
+		return "dependency"
+	default:
+		return "permanent"
+	}
+}
+
+// Retryable reports whether retrying a failure of the class can help.
+func (c Class) Retryable() bool {
+	return c == ClassTransient || c == ClassDependency
+}
+
+var (
+	classesMu sync.RWMutex
+	classes   = map[Code]Class{}
+)
+
+// RegisterClass sets the class of the errors with code. Codes without a
+// class are classified by their status: 4xx as client, 503 as dependency
+// and other statuses as permanent.
+func RegisterClass(code Code, class Class) {
+	classesMu.Lock()
+	defer classesMu.Unlock()
+
+	classes[code] = class
+}
+
+// ClassOf classifies err. A bag without a registered class is transient
+// when its cause is, errors other than bags are transient on timeouts and
+// permanent otherwise.
+func ClassOf(err error) Class {
+	var bag Bag
+	if errors.As(err, &bag) {
+		classesMu.RLock()
+		class, ok := classes[bag.Code]
+		classesMu.RUnlock()
+
+		switch {
+		case ok:
+			return class
+		case bag.cause != nil && ClassOf(bag.cause) == ClassTransient:
+			return ClassTransient
+		}
+
+		return classOfStatus(bag.Status())
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ClassTransient
+	}
+
+	return ClassPermanent
+}
+
+func classOfStatus(status int) Class {
+	switch {
+	case status == http.StatusServiceUnavailable:
+		return ClassDependency
+	case status >= http.StatusBadRequest && status < http.StatusInternalServerError:
+		return ClassClient
+	default:
+		return ClassPermanent
+	}
+}
@@ -5,6 +5,13 @@ import "encoding/json"
 type Bag struct {
 	Code    Code   `json:"code"`
 	Message string `json:"message"`
+	// Details tell which fields of the request are not valid.
+	Details []Detail `json:"details,omitempty"`
+
+	// cause and stack are kept for the logs only, they are unexported so
+	// they never reach a response.
+	cause error
+	stack []uintptr
 }
 
 func (b Bag) Error() string {
@@ -23,6 +30,9 @@ type Code int
 const (
 	BodyParserErrCode Code = 10001
 	ProcessingErrCode Code = 10002
+	// DependencyUnavailableErrCode is returned when a service the request
+	// needs, such as the product or stock api, could not answer it.
+	DependencyUnavailableErrCode Code = 10003
 )
 
 func BodyParser() Bag {
@@ -38,3 +48,10 @@ func Processing() Bag {
 		Message: "Error occurred when processing the request.",
 	}
 }
+
+func DependencyUnavailable() Bag {
+	return Bag{
+		Code:    DependencyUnavailableErrCode,
+		Message: "A service the request depends on is not available.",
+	}
+}
@@ -0,0 +1,67 @@
+package cerr_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/stretchr/testify/suite"
+)
+
+const pendingErrCode cerr.Code = 99101
+
+type BagTestSuite struct {
+	suite.Suite
+}
+
+func TestBagTestSuite(t *testing.T) {
+	suite.Run(t, new(BagTestSuite))
+}
+
+func (s *BagTestSuite) TestGivenWrappedBagThenItsCauseShouldBeFoundByErrors() {
+	cause := fmt.Errorf("query baskets: %w", context.DeadlineExceeded)
+
+	err := fmt.Errorf("add product: %w", cerr.Processing().Wrap(cause))
+
+	s.True(errors.Is(err, context.DeadlineExceeded))
+	s.True(errors.Is(err, cerr.Processing()))
+	s.False(errors.Is(err, cerr.DependencyUnavailable()))
+
+	var bag cerr.Bag
+	s.Require().True(errors.As(err, &bag))
+	s.Equal(cause, bag.Unwrap())
+}
+
+func (s *BagTestSuite) TestGivenWrappedBagThenTheCauseShouldNotBeMarshalled() {
+	bag := cerr.Processing().Wrap(errors.New("pq: connection refused"))
+
+	data, err := json.Marshal(bag)
+
+	s.Nil(err)
+	s.JSONEq(`{"code":10002,"message":"Error occurred when processing the request."}`, string(data))
+	s.NotContains(bag.Error(), "connection refused")
+	s.NotContains(fmt.Sprintf("%v", bag), "connection refused")
+	s.Contains(fmt.Sprintf("%+v", bag), "caused by: pq: connection refused")
+}
+
+func (s *BagTestSuite) TestGivenBagsThenTheyShouldBeClassified() {
+	cerr.RegisterStatus(pendingErrCode, http.StatusConflict)
+	cerr.RegisterClass(pendingErrCode, cerr.ClassTransient)
+
+	s.Equal(cerr.ClassClient, cerr.ClassOf(cerr.BodyParser()))
+	s.Equal(cerr.ClassDependency, cerr.ClassOf(cerr.DependencyUnavailable()))
+	s.Equal(cerr.ClassPermanent, cerr.ClassOf(cerr.Processing().Wrap(errors.New("constraint violated"))))
+	s.Equal(cerr.ClassTransient, cerr.ClassOf(cerr.Processing().Wrap(context.DeadlineExceeded)))
+	s.Equal(cerr.ClassTransient, cerr.ClassOf(cerr.Bag{Code: pendingErrCode}))
+}
+
+func (s *BagTestSuite) TestGivenPlainErrorsThenTheyShouldBeClassified() {
+	s.Equal(cerr.ClassTransient, cerr.ClassOf(fmt.Errorf("get product: %w", context.DeadlineExceeded)))
+	s.Equal(cerr.ClassPermanent, cerr.ClassOf(errors.New("no handler for outbox command")))
+	s.True(cerr.ClassDependency.Retryable())
+	s.False(cerr.ClassClient.Retryable())
+}
@@ -0,0 +1,47 @@
+package cerr
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ProblemContentType is the media type of the RFC 7807 problem details,
+// clients opt in to them through the Accept header.
+const ProblemContentType = "application/problem+json"
+
+const problemTypeFormat = "urn:pact-cdc-example:basket-service:error:%d"
+
+// Problem is the RFC 7807 rendering of a Bag. Code and Details are extension
+// members carrying what clients of the legacy shape already read.
+type Problem struct {
+	Type     string   `json:"type"`
+	Title    string   `json:"title"`
+	Status   int      `json:"status"`
+	Detail   string   `json:"detail"`
+	Instance string   `json:"instance,omitempty"`
+	TraceID  string   `json:"trace_id,omitempty"`
+	Code     Code     `json:"code"`
+	Details  []Detail `json:"details,omitempty"`
+}
+
+// Problem describes the bag as an occurrence at instance, the path of the
+// request which failed.
+func (b Bag) Problem(instance string, traceID string) Problem {
+	status := b.Status()
+
+	return Problem{
+		Type:     ProblemType(b.Code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   b.Message,
+		Instance: instance,
+		TraceID:  traceID,
+		Code:     b.Code,
+		Details:  b.Details,
+	}
+}
+
+// ProblemType returns the type uri of the problems with code.
+func ProblemType(code Code) string {
+	return fmt.Sprintf(problemTypeFormat, code)
+}
@@ -0,0 +1,27 @@
+package cerr
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// StackTrace returns the stack the bag was wrapped at, an empty string
+// unless the binary is built with the debug tag.
+func (b Bag) StackTrace() string {
+	if len(b.stack) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	frames := runtime.CallersFrames(b.stack)
+	for {
+		frame, more := frames.Next()
+		_, _ = fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,14 @@
+//go:build debug
+
+package cerr
+
+import "runtime"
+
+const maxStackDepth = 32
+
+// callers captures the stack above Wrap.
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
@@ -0,0 +1,17 @@
+//go:build debug
+
+package cerr_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+)
+
+func (s *BagTestSuite) TestGivenDebugBuildThenWrapShouldCaptureTheStack() {
+	bag := cerr.Processing().Wrap(errors.New("boom"))
+
+	s.Contains(bag.StackTrace(), "TestGivenDebugBuildThenWrapShouldCaptureTheStack")
+	s.Contains(fmt.Sprintf("%+v", bag), "stack_debug_test.go")
+}
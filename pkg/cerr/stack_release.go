@@ -0,0 +1,8 @@
+//go:build !debug
+
+package cerr
+
+// callers captures no stack, release builds do not pay for it.
+func callers() []uintptr {
+	return nil
+}
@@ -0,0 +1,42 @@
+package cerr
+
+import (
+	"net/http"
+	"sync"
+)
+
+var (
+	statusesMu sync.RWMutex
+	statuses   = map[Code]int{
+		BodyParserErrCode:            http.StatusBadRequest,
+		ProcessingErrCode:            http.StatusInternalServerError,
+		DependencyUnavailableErrCode: http.StatusServiceUnavailable,
+	}
+)
+
+// RegisterStatus sets the http status the errors with code are answered
+// with. Packages register the statuses of their codes on init.
+func RegisterStatus(code Code, status int) {
+	statusesMu.Lock()
+	defer statusesMu.Unlock()
+
+	statuses[code] = status
+}
+
+// Status returns the http status registered for code, codes nobody
+// registered are answered with 500.
+func Status(code Code) int {
+	statusesMu.RLock()
+	defer statusesMu.RUnlock()
+
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Status returns the http status registered for the code of the bag.
+func (b Bag) Status() int {
+	return Status(b.Code)
+}
@@ -0,0 +1,85 @@
+package cerr
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const ValidationErrCode Code = 10004
+
+func init() {
+	RegisterStatus(ValidationErrCode, http.StatusUnprocessableEntity)
+}
+
+// validation rules a Detail reports
+
+const (
+	RuleRequired = "required"
+	RuleMin      = "min"
+)
+
+// Detail describes why the value of a single request field was rejected.
+type Detail struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+
+	// params hold the parameters of the rule for localized messages.
+	params map[string]string
+}
+
+func (d Detail) render(template string) string {
+	replacements := []string{"{field}", d.Field}
+	for name, value := range d.params {
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+
+	return strings.NewReplacer(replacements...).Replace(template)
+}
+
+func Validation(details ...Detail) Bag {
+	return Bag{
+		Code:    ValidationErrCode,
+		Message: "Request is not valid.",
+		Details: details,
+	}
+}
+
+// Validator collects the details of every rule a request breaks, so clients
+// can fix all of its fields at once.
+type Validator struct {
+	details []Detail
+}
+
+func (v *Validator) Required(field string, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, RuleRequired, fmt.Sprintf("%s must be given.", field))
+	}
+}
+
+func (v *Validator) Min(field string, value int, min int) {
+	if value < min {
+		v.details = append(v.details, Detail{
+			Field:   field,
+			Rule:    RuleMin,
+			Message: fmt.Sprintf("%s must be at least %d.", field, min),
+			params:  map[string]string{"min": strconv.Itoa(min)},
+		})
+	}
+}
+
+func (v *Validator) Add(field string, rule string, message string) {
+	v.details = append(v.details, Detail{Field: field, Rule: rule, Message: message})
+}
+
+// Err returns the validation error with the collected details, nil when the
+// request broke no rule.
+func (v *Validator) Err() error {
+	if len(v.details) == 0 {
+		return nil
+	}
+
+	return Validation(v.details...)
+}
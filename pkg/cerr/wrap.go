@@ -0,0 +1,47 @@
+package cerr
+
+import (
+	"fmt"
+	"io"
+)
+
+// Wrap returns a copy of the bag caused by err. Debug builds capture the
+// stack of the caller as well.
+func (b Bag) Wrap(err error) Bag {
+	b.cause = err
+	b.stack = callers()
+	return b
+}
+
+func (b Bag) Unwrap() error {
+	return b.cause
+}
+
+// Is reports whether target is a bag with the same code, so errors.Is can
+// look for a code without comparing the messages and causes.
+func (b Bag) Is(target error) bool {
+	t, ok := target.(Bag)
+	return ok && t.Code == b.Code
+}
+
+// Format prints the json of the bag for %v and %s. %+v adds the cause and,
+// in debug builds, the stack the bag was wrapped at.
+func (b Bag) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		_, _ = io.WriteString(f, b.Error())
+		if !f.Flag('+') {
+			return
+		}
+		if b.cause != nil {
+			_, _ = fmt.Fprintf(f, "\ncaused by: %+v", b.cause)
+		}
+		if len(b.stack) > 0 {
+			_, _ = io.WriteString(f, "\n"+b.StackTrace())
+		}
+	case 's':
+		_, _ = io.WriteString(f, b.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(f, "%q", b.Error())
+	}
+}
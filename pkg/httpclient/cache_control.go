@@ -0,0 +1,43 @@
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CacheControl holds the response directives a client side cache cares about.
+type CacheControl struct {
+	MaxAge    time.Duration
+	HasMaxAge bool
+	NoStore   bool
+	NoCache   bool
+	ETag      string
+}
+
+func ParseCacheControl(header http.Header) CacheControl {
+	cc := CacheControl{ETag: header.Get(fiber.HeaderETag)}
+
+	for _, directive := range strings.Split(header.Get(fiber.HeaderCacheControl), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+
+		switch strings.ToLower(name) {
+		case "no-store":
+			cc.NoStore = true
+		case "no-cache":
+			cc.NoCache = true
+		case "max-age":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+			cc.MaxAge = time.Duration(seconds) * time.Second
+			cc.HasMaxAge = true
+		}
+	}
+
+	return cc
+}
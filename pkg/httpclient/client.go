@@ -6,11 +6,20 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/pact-cdc-example/basket-service/pkg/reqctx"
+	"github.com/pact-cdc-example/basket-service/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/pact-cdc-example/basket-service/pkg/httpclient")
+
 type Client interface {
 	Get(ctx context.Context, url string, headers map[string]string) ([]byte, error)
 	GetWithBody(
@@ -21,6 +30,26 @@ type Client interface {
 	) ([]byte, error)
 	Put(ctx context.Context, url string, headers map[string]string, body interface{}) ([]byte, error)
 	Post(ctx context.Context, url string, headers map[string]string, body interface{}) ([]byte, error)
+	Do(
+		ctx context.Context,
+		method string,
+		url string,
+		headers map[string]string,
+		body interface{},
+	) (*Response, error)
+}
+
+// Response is the raw result of a successful request. Callers which need
+// response headers, e.g. for caching, use Do instead of the verb helpers.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// NotModified reports whether the server answered a conditional request with 304.
+func (r *Response) NotModified() bool {
+	return r.StatusCode == http.StatusNotModified
 }
 
 type client struct {
@@ -34,32 +63,12 @@ func New() Client {
 }
 
 func (c *client) Get(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	resp, err := c.do(ctx, http.MethodGet, url, headers, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var bag cerr.Bag
-		if err := json.NewDecoder(resp.Body).Decode(&bag); err != nil {
-			return nil, err
-		}
-
-		return nil, bag
-	}
-
-	return io.ReadAll(resp.Body)
-
+	return resp.Body, nil
 }
 
 func (c *client) GetWithBody(
@@ -69,74 +78,71 @@ func (c *client) GetWithBody(
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, bytes.NewReader(bodyBytes))
+	resp, err := c.do(ctx, http.MethodGet, url, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
+	return resp.Body, nil
+}
 
-	resp, err := c.httpClient.Do(req)
+func (c *client) Put(ctx context.Context, url string, headers map[string]string, body interface{}) ([]byte, error) {
+	bodyBytes, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var bag cerr.Bag
-		if err = json.NewDecoder(resp.Body).Decode(&bag); err != nil {
-			return nil, err
-		}
 
-		return nil, bag
+	resp, err := c.do(ctx, http.MethodPut, url, headers, bodyBytes)
+	if err != nil {
+		return nil, err
 	}
 
-	return io.ReadAll(resp.Body)
-
+	return resp.Body, nil
 }
 
-func (c *client) Put(ctx context.Context, url string, headers map[string]string, body interface{}) ([]byte, error) {
+func (c *client) Post(ctx context.Context, url string, headers map[string]string, body interface{}) ([]byte, error) {
 	bodyBytes, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(bodyBytes))
+	resp, err := c.do(ctx, http.MethodPost, url, headers, bodyBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return resp.Body, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		var bag cerr.Bag
-		if err = json.NewDecoder(resp.Body).Decode(&bag); err != nil {
+func (c *client) Do(
+	ctx context.Context, method string, url string, headers map[string]string, body interface{}) (*Response, error) {
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		if bodyBytes, err = json.Marshal(body); err != nil {
 			return nil, err
 		}
-
-		return nil, bag
 	}
 
-	return io.ReadAll(resp.Body)
+	return c.do(ctx, method, url, headers, bodyBytes)
 }
 
-func (c *client) Post(ctx context.Context, url string, headers map[string]string, body interface{}) ([]byte, error) {
-	bodyBytes, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
+// do sends the request within a client span, whose trace context is
+// propagated to the called service through the traceparent header.
+func (c *client) do(
+	ctx context.Context, method string, url string, headers map[string]string, body []byte) (_ *Response, err error) {
+	ctx, span := tracer.Start(ctx, "HTTP "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPMethod(method), semconv.HTTPURL(url)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, err
 	}
@@ -145,22 +151,42 @@ func (c *client) Post(ctx context.Context, url string, headers map[string]string
 		req.Header.Set(k, v)
 	}
 
+	if requestID := reqctx.RequestID(ctx); requestID != "" {
+		req.Header.Set(reqctx.HeaderRequestID, requestID)
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		observeRequest(req.URL.Host, method, 0, start)
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	observeRequest(req.URL.Host, method, resp.StatusCode, start)
+	span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode))
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotModified {
 		var bag cerr.Bag
 		if err = json.NewDecoder(resp.Body).Decode(&bag); err != nil {
 			return nil, err
 		}
 
-		return nil, bag
+		return nil, &ResponseError{StatusCode: resp.StatusCode, Bag: bag}
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
 	}
 
-	return io.ReadAll(resp.Body)
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+	}, nil
 }
 
 var DefaultHeaders = map[string]string{
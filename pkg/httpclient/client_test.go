@@ -0,0 +1,111 @@
+package httpclient_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/pkg/httpclient"
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/pact-cdc-example/basket-service/pkg/reqctx"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ClientTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	exporter    *tracetest.InMemoryExporter
+	requestID   string
+	traceParent string
+}
+
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
+
+func (s *ClientTestSuite) SetupSuite() {
+	s.exporter = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(s.exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+func (s *ClientTestSuite) SetupTest() {
+	s.exporter.Reset()
+	s.requestID = ""
+	s.traceParent = ""
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requestID = r.Header.Get(reqctx.HeaderRequestID)
+		s.traceParent = r.Header.Get("traceparent")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":30003,"message":"not enough stock to reserve"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+}
+
+func (s *ClientTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ClientTestSuite) TestGivenRequestIDInContextThenItShouldBeForwarded() {
+	ctx := reqctx.WithRequestID(context.Background(), "r1")
+
+	_, err := httpclient.New().Get(ctx, s.server.URL, httpclient.DefaultHeaders)
+
+	s.Nil(err)
+	s.Equal("r1", s.requestID)
+}
+
+func (s *ClientTestSuite) TestGivenRequestThenItsLatencyShouldBeObservedByHostAndStatus() {
+	_, err := httpclient.New().Get(context.Background(), s.server.URL, httpclient.DefaultHeaders)
+	s.Nil(err)
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	host := strings.TrimPrefix(s.server.URL, "http://")
+	s.Contains(rec.Body.String(), fmt.Sprintf(
+		`basket_service_http_client_request_duration_seconds_count{host=%q,method="GET",status="200"} 1`, host))
+}
+
+func (s *ClientTestSuite) TestGivenRequestThenItShouldBeTracedAndTheTraceContextPropagated() {
+	_, err := httpclient.New().Get(context.Background(), s.server.URL+"/api/v1/products/p1", httpclient.DefaultHeaders)
+	s.Nil(err)
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+
+	span := spans[0]
+	s.Equal("HTTP GET", span.Name)
+	s.Equal(trace.SpanKindClient, span.SpanKind)
+	s.Contains(span.Attributes, attribute.Int("http.status_code", http.StatusOK))
+	s.Equal(fmt.Sprintf("00-%s-%s-01", span.SpanContext.TraceID(), span.SpanContext.SpanID()), s.traceParent)
+}
+
+func (s *ClientTestSuite) TestGivenErrorAnswerThenTheSpanShouldBeMarked() {
+	_, err := httpclient.New().Post(context.Background(), s.server.URL, httpclient.DefaultHeaders, struct{}{})
+	s.NotNil(err)
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Equal(codes.Error, spans[0].Status.Code)
+}
+
+func (s *ClientTestSuite) TestGivenNoRequestIDInContextThenNoneShouldBeSent() {
+	_, err := httpclient.New().Get(context.Background(), s.server.URL, httpclient.DefaultHeaders)
+
+	s.Nil(err)
+	s.Empty(s.requestID)
+}
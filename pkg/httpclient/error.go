@@ -0,0 +1,36 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+)
+
+// ResponseError is returned when the service answered with a status other
+// than 200 and 304. It unwraps to the bag of the answer, so callers match it
+// with errors.As and errors.Is like the bag itself.
+type ResponseError struct {
+	StatusCode int
+	Bag        cerr.Bag
+}
+
+func (e *ResponseError) Error() string {
+	return e.Bag.Error()
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.Bag
+}
+
+// IsServerError reports whether err is an answer of a failing service, a
+// 4xx answer means the request itself was wrong. Errors without an answer,
+// such as timeouts, are server errors too.
+func IsServerError(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	return err != nil
+}
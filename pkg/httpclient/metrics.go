@@ -0,0 +1,30 @@
+package httpclient
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// statusError labels the requests which got no response at all.
+const statusError = "error"
+
+var requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "http_client",
+	Name:      "request_duration_seconds",
+	Help:      "Latency of the requests to other services by host, method and status.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"host", "method", "status"})
+
+func observeRequest(host string, method string, statusCode int, start time.Time) {
+	status := statusError
+	if statusCode > 0 {
+		status = strconv.Itoa(statusCode)
+	}
+
+	requestDuration.WithLabelValues(host, method, status).Observe(time.Since(start).Seconds())
+}
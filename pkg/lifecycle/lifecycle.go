@@ -0,0 +1,146 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultStepTimeout bounds the steps which are given no timeout, so a
+// hanging step can not hold the shutdown until the process is killed.
+const defaultStepTimeout = 30 * time.Second
+
+// Step is one stage of the shutdown, e.g. stopping the http server.
+type Step struct {
+	Name string
+	Stop func(ctx context.Context) error
+	// Timeout bounds the step, the shutdown goes on with the next step
+	// when it is exceeded. Zero uses the StepTimeout of the manager.
+	Timeout time.Duration
+}
+
+type Manager interface {
+	// Run blocks until the process is signalled to stop or ctx is done and
+	// then shuts down.
+	Run(ctx context.Context) error
+	// Shutdown runs the steps one after another in their order. A failing
+	// step does not stop the later ones, their errors are joined.
+	Shutdown() error
+}
+
+type manager struct {
+	steps   []Step
+	signals []os.Signal
+	logger  *logrus.Logger
+}
+
+type NewManagerOpts struct {
+	Steps []Step
+	// Signals defaults to SIGINT and SIGTERM.
+	Signals []os.Signal
+	// StepTimeout is the timeout of the steps which have none, it defaults
+	// to 30 seconds.
+	StepTimeout time.Duration
+	L           *logrus.Logger
+}
+
+func NewManager(opts *NewManagerOpts) Manager {
+	m := &manager{
+		steps:   make([]Step, len(opts.Steps)),
+		signals: opts.Signals,
+		logger:  opts.L,
+	}
+
+	if len(m.signals) == 0 {
+		m.signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	stepTimeout := opts.StepTimeout
+	if stepTimeout <= 0 {
+		stepTimeout = defaultStepTimeout
+	}
+
+	for i, step := range opts.Steps {
+		if step.Timeout <= 0 {
+			step.Timeout = stepTimeout
+		}
+		m.steps[i] = step
+	}
+
+	return m
+}
+
+func (m *manager) Run(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, m.signals...)
+	defer signal.Stop(signals)
+
+	select {
+	case sig := <-signals:
+		m.logger.Infof("received %s, shutting down", sig)
+	case <-ctx.Done():
+		m.logger.Infof("shutting down: %v", ctx.Err())
+	}
+
+	return m.Shutdown()
+}
+
+func (m *manager) Shutdown() error {
+	var errs []error
+
+	for _, step := range m.steps {
+		start := time.Now()
+		log := m.logger.WithField("step", step.Name)
+
+		if err := m.runStep(step); err != nil {
+			log.Errorf("shutdown step failed: %v", err)
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name, err))
+			continue
+		}
+
+		log.WithField("duration_ms", time.Since(start).Milliseconds()).Info("shutdown step is done")
+	}
+
+	return errors.Join(errs...)
+}
+
+// runStep gives up on a step which ignores the cancellation of its context,
+// it is left running so the shutdown can not hang on it.
+func (m *manager) runStep(step Step) error {
+	ctx, cancel := context.WithTimeout(context.Background(), step.Timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- step.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("did not finish within %s", step.Timeout)
+	}
+}
+
+// Delay is the stop function of a step which only waits, e.g. so load
+// balancers notice the service is not ready before it stops serving.
+func Delay(d time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/pkg/lifecycle"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type LifecycleTestSuite struct {
+	suite.Suite
+	logger *logrus.Logger
+	order  []string
+}
+
+func TestLifecycleTestSuite(t *testing.T) {
+	suite.Run(t, new(LifecycleTestSuite))
+}
+
+func (s *LifecycleTestSuite) SetupTest() {
+	s.logger = logrus.New()
+	s.logger.SetLevel(logrus.PanicLevel)
+	s.order = nil
+}
+
+func (s *LifecycleTestSuite) step(name string, err error) lifecycle.Step {
+	return lifecycle.Step{Name: name, Stop: func(context.Context) error {
+		s.order = append(s.order, name)
+		return err
+	}}
+}
+
+func (s *LifecycleTestSuite) TestGivenStepsThenShutdownShouldRunThemInOrder() {
+	manager := lifecycle.NewManager(&lifecycle.NewManagerOpts{
+		Steps: []lifecycle.Step{s.step("readiness", nil), s.step("server", nil), s.step("database", nil)},
+		L:     s.logger,
+	})
+
+	s.Nil(manager.Shutdown())
+	s.Equal([]string{"readiness", "server", "database"}, s.order)
+}
+
+func (s *LifecycleTestSuite) TestGivenFailingStepThenLaterStepsShouldStillRun() {
+	manager := lifecycle.NewManager(&lifecycle.NewManagerOpts{
+		Steps: []lifecycle.Step{s.step("server", errors.New("listener is closed")), s.step("database", nil)},
+		L:     s.logger,
+	})
+
+	err := manager.Shutdown()
+
+	s.EqualError(err, "server: listener is closed")
+	s.Equal([]string{"server", "database"}, s.order)
+}
+
+func (s *LifecycleTestSuite) TestGivenStepExceedingItsTimeoutThenShutdownShouldGoOn() {
+	hanging := lifecycle.Step{
+		Name:    "workers",
+		Timeout: 10 * time.Millisecond,
+		Stop: func(context.Context) error {
+			select {}
+		},
+	}
+
+	manager := lifecycle.NewManager(&lifecycle.NewManagerOpts{
+		Steps: []lifecycle.Step{hanging, s.step("database", nil)},
+		L:     s.logger,
+	})
+
+	err := manager.Shutdown()
+
+	s.EqualError(err, "workers: did not finish within 10ms")
+	s.Equal([]string{"database"}, s.order)
+}
+
+func (s *LifecycleTestSuite) TestGivenStepWithoutTimeoutThenTheDefaultShouldBoundIt() {
+	hanging := lifecycle.Step{
+		Name: "workers",
+		Stop: func(context.Context) error {
+			select {}
+		},
+	}
+
+	manager := lifecycle.NewManager(&lifecycle.NewManagerOpts{
+		Steps:       []lifecycle.Step{hanging, s.step("database", nil)},
+		StepTimeout: 10 * time.Millisecond,
+		L:           s.logger,
+	})
+
+	err := manager.Shutdown()
+
+	s.EqualError(err, "workers: did not finish within 10ms")
+	s.Equal([]string{"database"}, s.order)
+}
+
+func (s *LifecycleTestSuite) TestGivenDoneContextThenRunShouldShutDown() {
+	manager := lifecycle.NewManager(&lifecycle.NewManagerOpts{
+		Steps: []lifecycle.Step{s.step("server", nil)},
+		L:     s.logger,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.Nil(manager.Run(ctx))
+	s.Equal([]string{"server"}, s.order)
+}
+
+func (s *LifecycleTestSuite) TestGivenDelayThenItShouldWaitUntilContextIsDone() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	s.ErrorIs(lifecycle.Delay(time.Minute)(ctx), context.DeadlineExceeded)
+	s.Nil(lifecycle.Delay(time.Millisecond)(context.Background()))
+}
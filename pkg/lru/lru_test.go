@@ -0,0 +1,69 @@
+package lru_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/pkg/lru"
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheTestSuite struct {
+	suite.Suite
+	cache *lru.Cache[string, int]
+}
+
+func TestCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(CacheTestSuite))
+}
+
+func (s *CacheTestSuite) SetupTest() {
+	s.cache = lru.New[string, int](2)
+}
+
+func (s *CacheTestSuite) TestGivenFullCacheThenItShouldEvictLeastRecentlyUsedEntry() {
+	s.cache.Add("a", 1)
+	s.cache.Add("b", 2)
+	_, _ = s.cache.Get("a")
+	s.cache.Add("c", 3)
+
+	_, found := s.cache.Get("b")
+	s.False(found)
+
+	value, found := s.cache.Get("a")
+	s.True(found)
+	s.Equal(1, value)
+	s.Equal(2, s.cache.Len())
+}
+
+func (s *CacheTestSuite) TestGivenExistingKeyThenAddShouldReplaceValueWithoutEvicting() {
+	s.cache.Add("a", 1)
+	s.cache.Add("b", 2)
+	s.cache.Add("a", 10)
+
+	value, _ := s.cache.Get("a")
+	s.Equal(10, value)
+
+	_, found := s.cache.Get("b")
+	s.True(found)
+	s.Equal(2, s.cache.Len())
+}
+
+func (s *CacheTestSuite) TestGivenRemovedKeyThenItShouldNotBeFound() {
+	s.cache.Add("a", 1)
+	s.cache.Remove("a")
+	s.cache.Remove("unknown")
+
+	_, found := s.cache.Get("a")
+	s.False(found)
+	s.Equal(0, s.cache.Len())
+}
+
+func (s *CacheTestSuite) TestGivenNonPositiveCapacityThenItShouldKeepOneEntry() {
+	cache := lru.New[string, int](0)
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+
+	_, found := cache.Get("a")
+	s.False(found)
+	s.Equal(1, cache.Len())
+}
@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the names of the metrics of the service.
+const Namespace = "basket_service"
+
+// Handler serves the metrics every package registered with the default
+// prometheus registry, together with the go runtime and process metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
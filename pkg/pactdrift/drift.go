@@ -0,0 +1,189 @@
+package pactdrift
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pact-cdc-example/basket-service/pkg/pactstub"
+)
+
+// Contract binds the interactions with Description to the types the client
+// sends and decodes. Response is compared with the successful responses
+// only, error responses are decoded into cerr.Bag by the http client.
+type Contract struct {
+	Description string
+	Request     interface{}
+	Response    interface{}
+}
+
+type Mismatch struct {
+	Interaction string
+	Field       string
+	Reason      string
+}
+
+func (m Mismatch) String() string {
+	if m.Field == "" {
+		return fmt.Sprintf("%s: %s", m.Interaction, m.Reason)
+	}
+
+	return fmt.Sprintf("%s: field %q %s", m.Interaction, m.Field, m.Reason)
+}
+
+// Check compares the bodies of the interactions in pacts with the types of
+// their contracts. An interaction without a contract is a mismatch too, so
+// a new interaction can not slip in unchecked.
+func Check(pacts []pactstub.Pact, contracts []Contract) []Mismatch {
+	byDescription := make(map[string]Contract, len(contracts))
+	for _, contract := range contracts {
+		byDescription[contract.Description] = contract
+	}
+
+	var mismatches []Mismatch
+	for _, pact := range pacts {
+		for _, interaction := range pact.Interactions {
+			name := fmt.Sprintf("%s %q given %q", pact.Provider.Name, interaction.Description, interaction.ProviderState)
+
+			contract, ok := byDescription[interaction.Description]
+			if !ok {
+				mismatches = append(mismatches, Mismatch{Interaction: name, Reason: "has no contract"})
+				continue
+			}
+
+			if contract.Request != nil && len(interaction.Request.Body) > 0 {
+				mismatches = append(mismatches, compareBody(name+" request", contract.Request, interaction.Request.Body)...)
+			}
+
+			if contract.Response != nil && interaction.Response.Status < http.StatusBadRequest {
+				mismatches = append(mismatches, compareBody(name+" response", contract.Response, interaction.Response.Body)...)
+			}
+		}
+	}
+
+	return mismatches
+}
+
+func compareBody(name string, v interface{}, body []byte) []Mismatch {
+	pactSchema, err := SchemaOfJSON(body)
+	if err != nil {
+		return []Mismatch{{Interaction: name, Reason: fmt.Sprintf("has invalid body: %v", err)}}
+	}
+
+	return Compare(name, reflect.TypeOf(v).String(), SchemaOf(v), pactSchema)
+}
+
+// Compare reports the fields of the pact body which are not in the struct or
+// are of another kind, and the fields of the struct which are not in the pact
+// body although json never omits them.
+func Compare(name string, typeName string, structSchema Schema, pactSchema Schema) []Mismatch {
+	var mismatches []Mismatch
+
+	for _, path := range sortedPaths(pactSchema) {
+		pactField := pactSchema[path]
+		structField, ok := structSchema[path]
+		if !ok {
+			if parentMissing(path, structSchema) {
+				continue
+			}
+			reason := fmt.Sprintf("of the pact is not in %s", typeName)
+			if similar := similarPath(path, structSchema); similar != "" {
+				reason += fmt.Sprintf(", it has %q", similar)
+			}
+			mismatches = append(mismatches, Mismatch{Interaction: name, Field: path, Reason: reason})
+			continue
+		}
+
+		if !kindsMatch(structField.Kind, pactField.Kind) {
+			mismatches = append(mismatches, Mismatch{
+				Interaction: name,
+				Field:       path,
+				Reason:      fmt.Sprintf("is %s in the pact but %s in %s", pactField.Kind, structField.Kind, typeName),
+			})
+		}
+	}
+
+	for _, path := range sortedPaths(structSchema) {
+		structField := structSchema[path]
+		if structField.Optional || optionalParent(path, structSchema) {
+			continue
+		}
+		if _, ok := pactSchema[path]; ok || parentMissing(path, pactSchema) {
+			continue
+		}
+
+		mismatches = append(mismatches, Mismatch{
+			Interaction: name,
+			Field:       path,
+			Reason:      fmt.Sprintf("of %s is not in the pact", typeName),
+		})
+	}
+
+	return mismatches
+}
+
+func kindsMatch(structKind Kind, pactKind Kind) bool {
+	return structKind == pactKind || structKind == KindAny || pactKind == KindNull
+}
+
+// parentMissing reports whether a parent of path is not in schema, only the
+// topmost missing field is reported.
+func parentMissing(path string, schema Schema) bool {
+	for parent := parentPath(path); parent != ""; parent = parentPath(parent) {
+		if _, ok := schema[parent]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func optionalParent(path string, schema Schema) bool {
+	for parent := parentPath(path); parent != ""; parent = parentPath(parent) {
+		if schema[parent].Optional {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parentPath(path string) string {
+	if strings.HasSuffix(path, "[*]") {
+		return strings.TrimSuffix(path, "[*]")
+	}
+
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+
+	return ""
+}
+
+// similarPath finds a field of schema which differs from path only in casing
+// or underscores, such as productId for product_id.
+func similarPath(path string, schema Schema) string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+	}
+
+	for candidate := range schema {
+		if normalize(candidate) == normalize(path) {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+func sortedPaths(schema Schema) []string {
+	paths := make([]string, 0, len(schema))
+	for path := range schema {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
@@ -0,0 +1,81 @@
+package pactdrift_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/pkg/pactdrift"
+	"github.com/pact-cdc-example/basket-service/pkg/pactstub"
+	"github.com/stretchr/testify/suite"
+)
+
+type item struct {
+	ProductID string    `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	AddedAt   time.Time `json:"added_at"`
+	Note      *string   `json:"note,omitempty"`
+}
+
+type order struct {
+	ID    string `json:"id"`
+	Items []item `json:"items"`
+}
+
+type DriftTestSuite struct {
+	suite.Suite
+}
+
+func TestDriftTestSuite(t *testing.T) {
+	suite.Run(t, new(DriftTestSuite))
+}
+
+func (s *DriftTestSuite) TestGivenMatchingBodyThenItShouldReportNothing() {
+	mismatches := s.check(`{"id":"o1","items":[{"product_id":"p1","quantity":2,"added_at":"2022-03-01T10:00:00Z"}]}`)
+
+	s.Empty(mismatches)
+}
+
+func (s *DriftTestSuite) TestGivenWrongCasingThenItShouldReportTheField() {
+	mismatches := s.check(`{"id":"o1","items":[{"productId":"p1","quantity":2,"added_at":"2022-03-01T10:00:00Z"}]}`)
+
+	s.Equal([]string{
+		`StockService "order" given "" response: field "items[*].productId" of the pact is not in pactdrift_test.order, it has "items[*].product_id"`,
+		`StockService "order" given "" response: field "items[*].product_id" of pactdrift_test.order is not in the pact`,
+	}, mismatchStrings(mismatches))
+}
+
+func (s *DriftTestSuite) TestGivenTypeChangeThenItShouldReportTheField() {
+	mismatches := s.check(`{"id":"o1","items":[{"product_id":"p1","quantity":"2","added_at":"2022-03-01T10:00:00Z"}]}`)
+
+	s.Equal([]string{
+		`StockService "order" given "" response: field "items[*].quantity" is string in the pact but number in pactdrift_test.order`,
+	}, mismatchStrings(mismatches))
+}
+
+func (s *DriftTestSuite) TestGivenInteractionWithoutContractThenItShouldBeReported() {
+	mismatches := pactdrift.Check([]pactstub.Pact{{
+		Provider:     pactstub.Pacticipant{Name: "StockService"},
+		Interactions: []pactstub.Interaction{{Description: "unknown"}},
+	}}, nil)
+
+	s.Equal([]string{`StockService "unknown" given "": has no contract`}, mismatchStrings(mismatches))
+}
+
+func (s *DriftTestSuite) check(body string) []pactdrift.Mismatch {
+	return pactdrift.Check([]pactstub.Pact{{
+		Provider: pactstub.Pacticipant{Name: "StockService"},
+		Interactions: []pactstub.Interaction{{
+			Description: "order",
+			Response:    pactstub.Response{Status: 200, Body: []byte(body)},
+		}},
+	}}, []pactdrift.Contract{{Description: "order", Response: order{}}})
+}
+
+func mismatchStrings(mismatches []pactdrift.Mismatch) []string {
+	out := make([]string, len(mismatches))
+	for i, m := range mismatches {
+		out[i] = m.String()
+	}
+
+	return out
+}
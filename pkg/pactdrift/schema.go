@@ -0,0 +1,185 @@
+package pactdrift
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Kind is the json type of a field.
+type Kind string
+
+const (
+	KindString Kind = "string"
+	KindNumber Kind = "number"
+	KindBool   Kind = "boolean"
+	KindObject Kind = "object"
+	KindArray  Kind = "array"
+	KindNull   Kind = "null"
+	// KindAny is the kind of interface fields, it matches every kind.
+	KindAny Kind = "any"
+)
+
+type Field struct {
+	Kind Kind
+	// Optional is set on the fields json may omit, omitempty and pointer
+	// fields of structs.
+	Optional bool
+}
+
+// Schema maps the path of every field in a json document to its field.
+// Array elements are described once under the [*] path of the array.
+type Schema map[string]Field
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf describes how encoding/json encodes v.
+func SchemaOf(v interface{}) Schema {
+	schema := Schema{}
+	describeType(schema, "", reflect.TypeOf(v), false)
+	return schema
+}
+
+func describeType(schema Schema, path string, t reflect.Type, optional bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		optional = true
+	}
+
+	if path != "" {
+		schema[path] = Field{Kind: kindOfType(t), Optional: optional}
+	}
+
+	switch {
+	case t == timeType:
+	case t.Kind() == reflect.Struct:
+		describeStruct(schema, path, t)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			describeType(schema, path+"[*]", t.Elem(), false)
+		}
+	}
+}
+
+func describeStruct(schema Schema, path string, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, omitempty, skip := jsonName(f)
+		if skip {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			describeType(schema, path, f.Type, false)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		describeType(schema, join(path, name), f.Type, omitempty)
+	}
+}
+
+func jsonName(f reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+
+	return parts[0], omitempty, false
+}
+
+func kindOfType(t reflect.Type) Kind {
+	if t == timeType {
+		return KindString
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return KindString
+	case reflect.Bool:
+		return KindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return KindNumber
+	case reflect.Struct, reflect.Map:
+		return KindObject
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return KindString
+		}
+		return KindArray
+	default:
+		return KindAny
+	}
+}
+
+// SchemaOfJSON describes the example document of a pact body, arrays are
+// described by their first element.
+func SchemaOfJSON(body []byte) (Schema, error) {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+
+	schema := Schema{}
+	describeValue(schema, "", v)
+	return schema, nil
+}
+
+func describeValue(schema Schema, path string, v interface{}) {
+	if path != "" {
+		schema[path] = Field{Kind: kindOfValue(v)}
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			describeValue(schema, join(path, key), child)
+		}
+	case []interface{}:
+		if len(value) > 0 {
+			describeValue(schema, path+"[*]", value[0])
+		}
+	}
+}
+
+func kindOfValue(v interface{}) Kind {
+	switch v.(type) {
+	case string:
+		return KindString
+	case float64:
+		return KindNumber
+	case bool:
+		return KindBool
+	case map[string]interface{}:
+		return KindObject
+	case []interface{}:
+		return KindArray
+	default:
+		return KindNull
+	}
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,119 @@
+package pactstub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// matchPath compares the paths segment by segment. The pacts record the ids
+// the consumer tests generated, so a uuid segment matches any segment.
+func matchPath(expected string, actual string) bool {
+	expectedSegments := strings.Split(strings.Trim(expected, "/"), "/")
+	actualSegments := strings.Split(strings.Trim(actual, "/"), "/")
+
+	if len(expectedSegments) != len(actualSegments) {
+		return false
+	}
+
+	for i, segment := range expectedSegments {
+		if segment == actualSegments[i] {
+			continue
+		}
+		if !uuidPattern.MatchString(segment) || actualSegments[i] == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchBody reports whether actual has the shape of the expected request
+// body. Like the pact mock service it does not allow keys which the
+// interaction does not expect, values are compared by type where a matching
+// rule asks so and by value otherwise.
+func matchBody(expected json.RawMessage, actual []byte, rules map[string]MatchingRule) bool {
+	if len(bytes.TrimSpace(expected)) == 0 {
+		return true
+	}
+
+	var expectedValue, actualValue interface{}
+	if err := json.Unmarshal(expected, &expectedValue); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(actual, &actualValue); err != nil {
+		return false
+	}
+
+	return matchValue("$.body", expectedValue, actualValue, rules)
+}
+
+func matchValue(path string, expected interface{}, actual interface{}, rules map[string]MatchingRule) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for key, value := range e {
+			actualValue, ok := a[key]
+			if !ok || !matchValue(path+"."+key, value, actualValue, rules) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return false
+		}
+		if rule, ok := rules[path]; ok && rule.Min != nil {
+			if len(a) < *rule.Min || len(e) == 0 {
+				return false
+			}
+			for _, value := range a {
+				if !matchValue(path+"[*]", e[0], value, rules) {
+					return false
+				}
+			}
+			return true
+		}
+		if len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !matchValue(fmt.Sprintf("%s[%d]", path, i), e[i], a[i], rules) {
+				return false
+			}
+		}
+		return true
+	default:
+		if hasTypeRule(path, rules) {
+			return sameKind(expected, actual)
+		}
+		return expected == actual
+	}
+}
+
+// hasTypeRule looks for a type rule on path or on the wildcards EachLike
+// writes, such as $.body.products[*].* for the keys of the elements and
+// $.body.ids[*].* for the elements themselves.
+func hasTypeRule(path string, rules map[string]MatchingRule) bool {
+	if rules[path].Match == "type" || rules[path+".*"].Match == "type" {
+		return true
+	}
+
+	if i := strings.LastIndexAny(path, ".["); i > 0 {
+		return rules[path[:i]+".*"].Match == "type"
+	}
+
+	return false
+}
+
+func sameKind(a interface{}, b interface{}) bool {
+	return fmt.Sprintf("%T", a) == fmt.Sprintf("%T", b)
+}
@@ -0,0 +1,75 @@
+package pactstub
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Pact is the part of a pact file the stub server needs.
+type Pact struct {
+	Consumer     Pacticipant   `json:"consumer"`
+	Provider     Pacticipant   `json:"provider"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Pacticipant struct {
+	Name string `json:"name"`
+}
+
+type Interaction struct {
+	Description   string   `json:"description"`
+	ProviderState string   `json:"providerState"`
+	Request       Request  `json:"request"`
+	Response      Response `json:"response"`
+}
+
+type Request struct {
+	Method        string                  `json:"method"`
+	Path          string                  `json:"path"`
+	Headers       map[string]string       `json:"headers"`
+	Body          json.RawMessage         `json:"body"`
+	MatchingRules map[string]MatchingRule `json:"matchingRules"`
+}
+
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// MatchingRule is a pact specification v2 matching rule.
+type MatchingRule struct {
+	Match string `json:"match"`
+	Min   *int   `json:"min"`
+}
+
+// Load reads the pact files matching pattern, files without http interactions
+// such as message pacts are skipped.
+func Load(pattern string) ([]Pact, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var pacts []Pact
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var pact Pact
+		if err = json.Unmarshal(content, &pact); err != nil {
+			return nil, err
+		}
+
+		if len(pact.Interactions) > 0 {
+			pacts = append(pacts, pact)
+		}
+	}
+
+	return pacts, nil
+}
@@ -0,0 +1,110 @@
+package pactstub
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// replay returns the recorded response body with the ids of the request in
+// place of the recorded ones. The pacts record the ids the consumer tests
+// generated, a client matching the answer to its request would find none of
+// them.
+func replay(interaction *Interaction, path string, body []byte) []byte {
+	recorded := interaction.Response.Body
+	if len(bytes.TrimSpace(recorded)) == 0 {
+		return recorded
+	}
+
+	ids := requestedIDs(interaction.Request, path, body)
+	if len(ids) == 0 {
+		return recorded
+	}
+
+	var response interface{}
+	if err := json.Unmarshal(recorded, &response); err != nil {
+		return recorded
+	}
+
+	replayed, err := json.Marshal(replaceIDs(response, ids))
+	if err != nil {
+		return recorded
+	}
+
+	return replayed
+}
+
+// requestedIDs maps the recorded ids to the ones requested instead, the id
+// segments of the path and the ids of a bulk request body.
+func requestedIDs(recorded Request, path string, body []byte) map[string][]string {
+	ids := make(map[string][]string)
+
+	recordedSegments := strings.Split(strings.Trim(recorded.Path, "/"), "/")
+	actualSegments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range recordedSegments {
+		if i < len(actualSegments) && uuidPattern.MatchString(segment) && segment != actualSegments[i] {
+			ids[segment] = []string{actualSegments[i]}
+		}
+	}
+
+	var recordedBody, actualBody struct {
+		IDs []string `json:"ids"`
+	}
+	if json.Unmarshal(recorded.Body, &recordedBody) != nil || json.Unmarshal(body, &actualBody) != nil {
+		return ids
+	}
+
+	if len(actualBody.IDs) > 0 {
+		for _, id := range recordedBody.IDs {
+			ids[id] = actualBody.IDs
+		}
+	}
+
+	return ids
+}
+
+// replaceIDs sets the requested ids on the records of value. A list of records
+// with a recorded id is answered with a copy of its first record per
+// requested id, like a provider answering a bulk request.
+func replaceIDs(value interface{}, ids map[string][]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if requested := ids[idOf(v)]; len(requested) == 1 {
+			v["id"] = requested[0]
+		}
+		for key, field := range v {
+			v[key] = replaceIDs(field, ids)
+		}
+		return v
+	case []interface{}:
+		for _, element := range v {
+			record, ok := element.(map[string]interface{})
+			if !ok || ids[idOf(record)] == nil {
+				continue
+			}
+
+			requested := ids[idOf(record)]
+			records := make([]interface{}, len(requested))
+			for i, id := range requested {
+				copied := make(map[string]interface{}, len(record))
+				for key, field := range record {
+					copied[key] = field
+				}
+				copied["id"] = id
+				records[i] = copied
+			}
+			return records
+		}
+		for i := range v {
+			v[i] = replaceIDs(v[i], ids)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func idOf(record map[string]interface{}) string {
+	id, _ := record["id"].(string)
+	return id
+}
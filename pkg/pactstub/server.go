@@ -0,0 +1,98 @@
+package pactstub
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProviderStateHeader picks the interaction to answer with when more than one
+// interaction of the pact matches the request.
+const ProviderStateHeader = "X-Pact-Provider-State"
+
+type server struct {
+	pact   Pact
+	states []string
+	logger *logrus.Logger
+}
+
+type NewServerOpts struct {
+	Pact Pact
+	// States are the provider states preferred when the request does not
+	// name one, in order.
+	States []string
+	L      *logrus.Logger
+}
+
+// NewServer returns a handler which answers the requests with the responses
+// recorded in the pact, carrying the ids the request asks for. A request
+// matching none of the interactions gets 404.
+func NewServer(opts *NewServerOpts) http.Handler {
+	return &server{
+		pact:   opts.Pact,
+		states: opts.States,
+		logger: opts.L,
+	}
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	interaction := s.find(r, body)
+	if interaction == nil {
+		s.logger.WithField("provider", s.pact.Provider.Name).
+			Warnf("no interaction matches %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	for k, v := range interaction.Response.Headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(interaction.Response.Status)
+	_, _ = w.Write(replay(interaction, r.URL.Path, body))
+}
+
+// find picks the interaction in the state the request asks for, else in the
+// first preferred state, else the first successful one.
+func (s *server) find(r *http.Request, body []byte) *Interaction {
+	var candidates []*Interaction
+	for i := range s.pact.Interactions {
+		interaction := &s.pact.Interactions[i]
+		if interaction.Request.Method == r.Method &&
+			matchPath(interaction.Request.Path, r.URL.Path) &&
+			matchBody(interaction.Request.Body, body, interaction.Request.MatchingRules) {
+			candidates = append(candidates, interaction)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	states := s.states
+	if state := r.Header.Get(ProviderStateHeader); state != "" {
+		states = append([]string{state}, states...)
+	}
+
+	for _, state := range states {
+		for _, candidate := range candidates {
+			if candidate.ProviderState == state {
+				return candidate
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Response.Status < http.StatusBadRequest {
+			return candidate
+		}
+	}
+
+	return candidates[0]
+}
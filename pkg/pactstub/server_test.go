@@ -0,0 +1,154 @@
+package pactstub_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pact-cdc-example/basket-service/pkg/pactstub"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type StubServerTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func TestStubServerTestSuite(t *testing.T) {
+	suite.Run(t, new(StubServerTestSuite))
+}
+
+func (s *StubServerTestSuite) SetupTest() {
+	pacts, err := pactstub.Load("../../app/stock/pacts/*.json")
+	s.Require().Nil(err)
+	s.Require().Len(pacts, 1)
+
+	s.server = httptest.NewServer(pactstub.NewServer(&pactstub.NewServerOpts{
+		Pact:   pacts[0],
+		States: []string{"i get true"},
+		L:      logrus.New(),
+	}))
+}
+
+func (s *StubServerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *StubServerTestSuite) TestGivenRequestOfRecordedShapeThenItShouldAnswerWithPreferredState() {
+	status, body := s.post("/api/v1/stocks/availability", `{"product_id":"p1","quantity":3}`, "")
+
+	s.Equal(http.StatusOK, status)
+	s.JSONEq(`{"is_available":true}`, body)
+}
+
+func (s *StubServerTestSuite) TestGivenProviderStateHeaderThenItShouldAnswerWithThatState() {
+	status, body := s.post("/api/v1/stocks/availability", `{"product_id":"p1","quantity":3}`,
+		"i get no stock information found error if no stock information found for given product id")
+
+	s.Equal(http.StatusBadRequest, status)
+	s.Contains(body, "30001")
+}
+
+func (s *StubServerTestSuite) TestGivenMissingKeyThenItShouldMatchTheValidationInteraction() {
+	status, body := s.post("/api/v1/stocks/availability", `{"quantity":3}`, "")
+
+	s.Equal(http.StatusBadRequest, status)
+	s.Contains(body, "30000")
+}
+
+func (s *StubServerTestSuite) TestGivenUnknownIDInPathThenItShouldMatchRecordedPath() {
+	res, err := http.Get(s.server.URL + "/api/v1/stocks/0b1c2d3e-4f5a-4b6c-8d7e-9f0a1b2c3d4e")
+	s.Require().Nil(err)
+	defer res.Body.Close()
+
+	s.Equal(http.StatusOK, res.StatusCode)
+}
+
+func (s *StubServerTestSuite) TestGivenUnrecordedRequestThenItShouldAnswerNotFound() {
+	status, _ := s.post("/api/v1/stocks/unknown", `{}`, "")
+
+	s.Equal(http.StatusNotFound, status)
+}
+
+func (s *StubServerTestSuite) post(path string, body string, state string) (int, string) {
+	req, err := http.NewRequest(http.MethodPost, s.server.URL+path, strings.NewReader(body))
+	s.Require().Nil(err)
+	req.Header.Set("Content-Type", "application/json")
+	if state != "" {
+		req.Header.Set(pactstub.ProviderStateHeader, state)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	s.Require().Nil(err)
+	defer res.Body.Close()
+
+	content, err := io.ReadAll(res.Body)
+	s.Require().Nil(err)
+
+	return res.StatusCode, string(content)
+}
+
+type ProductStubServerTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func TestProductStubServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ProductStubServerTestSuite))
+}
+
+func (s *ProductStubServerTestSuite) SetupTest() {
+	pacts, err := pactstub.Load("../../app/product/pacts/*.json")
+	s.Require().Nil(err)
+	s.Require().Len(pacts, 1)
+
+	s.server = httptest.NewServer(pactstub.NewServer(&pactstub.NewServerOpts{
+		Pact: pacts[0],
+		L:    logrus.New(),
+	}))
+}
+
+func (s *ProductStubServerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ProductStubServerTestSuite) TestGivenBulkRequestThenItShouldAnswerWithAProductPerRequestedID() {
+	res, err := http.Post(s.server.URL+"/api/v1/products/bulk", "application/json",
+		strings.NewReader(`{"ids":["p1","p2","p3"]}`))
+	s.Require().Nil(err)
+	defer res.Body.Close()
+
+	var body struct {
+		Products []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"products"`
+	}
+	s.Require().Nil(json.NewDecoder(res.Body).Decode(&body))
+
+	s.Equal(http.StatusOK, res.StatusCode)
+	s.Require().Len(body.Products, 3)
+	for i, id := range []string{"p1", "p2", "p3"} {
+		s.Equal(id, body.Products[i].ID)
+		s.NotEmpty(body.Products[i].Name)
+	}
+}
+
+func (s *ProductStubServerTestSuite) TestGivenIDInPathThenItShouldAnswerWithThatProduct() {
+	id := "0b1c2d3e-4f5a-4b6c-8d7e-9f0a1b2c3d4e"
+	res, err := http.Get(s.server.URL + "/api/v1/products/" + id)
+	s.Require().Nil(err)
+	defer res.Body.Close()
+
+	var body struct {
+		ID string `json:"id"`
+	}
+	s.Require().Nil(json.NewDecoder(res.Body).Decode(&body))
+
+	s.Equal(http.StatusOK, res.StatusCode)
+	s.Equal(id, body.ID)
+}
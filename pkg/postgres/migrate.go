@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const createSchemaMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INT PRIMARY KEY,
+	applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+)`
+
+type migration struct {
+	name    string
+	version int
+}
+
+// migrationLockKey is the key of the advisory lock the replicas of the
+// service take while they migrate, any fixed number does.
+const migrationLockKey int64 = 4_720_319_855
+
+// Migrate applies the *.sql files of fsys which are not recorded in
+// schema_migrations yet, in the order of their version. A file name must
+// start with its version, e.g. 0001_create_baskets.sql. Replicas starting
+// together migrate one after another, each holding an advisory lock on its
+// own connection of db.
+func Migrate(ctx context.Context, db *sql.DB, fsys fs.FS) (err error) {
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return err
+	}
+
+	// the advisory lock belongs to the session, so every statement runs on
+	// the connection which holds it.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get connection to migrate: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("could not lock migrations: %w", err)
+	}
+	defer func() {
+		if _, unlockErr := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey); unlockErr != nil {
+			err = errors.Join(err, fmt.Errorf("could not unlock migrations: %w", unlockErr))
+		}
+	}()
+
+	if _, err = conn.ExecContext(ctx, createSchemaMigrationsTable); err != nil {
+		return fmt.Errorf("could not create schema migrations table: %w", err)
+	}
+
+	for _, m := range migrations {
+		var applied bool
+		if err = conn.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, m.version,
+		).Scan(&applied); err != nil {
+			return fmt.Errorf("could not check migration %s: %w", m.name, err)
+		}
+
+		if applied {
+			continue
+		}
+
+		if err = applyMigration(ctx, conn, fsys, m.name, m.version); err != nil {
+			return fmt.Errorf("could not apply migration %s: %w", m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// readMigrations returns the *.sql files of fsys sorted by their version,
+// the file names sort differently once the versions have more digits than
+// the padding.
+func readMigrations(fsys fs.FS) ([]migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(names))
+	namesByVersion := make(map[int]string, len(names))
+	for _, name := range names {
+		version, err := migrationVersion(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, ok := namesByVersion[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", other, name)
+		}
+		namesByVersion[version] = name
+
+		migrations = append(migrations, migration{name: name, version: version})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, fsys fs.FS, name string, version int) error {
+	query, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, string(query)); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func migrationVersion(name string) (int, error) {
+	prefix, _, _ := strings.Cut(name, "_")
+
+	version, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("migration %s does not start with a version", name)
+	}
+
+	return version, nil
+}
+
+// LatestVersion returns the highest version of the *.sql files of fsys, the
+// version a database is at after Migrate applied all of them.
+func LatestVersion(fsys fs.FS) (int, error) {
+	migrations, err := readMigrations(fsys)
+	if err != nil || len(migrations) == 0 {
+		return 0, err
+	}
+
+	return migrations[len(migrations)-1].version, nil
+}
+
+// CurrentVersion returns the highest version recorded in schema_migrations,
+// 0 when no migration is applied yet.
+func CurrentVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`,
+	).Scan(&version); err != nil {
+		return 0, fmt.Errorf("could not read schema version: %w", err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,164 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pact-cdc-example/basket-service/pkg/postgres"
+	"github.com/stretchr/testify/suite"
+)
+
+type MigrateTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+}
+
+func TestMigrateTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrateTestSuite))
+}
+
+func (s *MigrateTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	s.Require().Nil(err)
+}
+
+func (s *MigrateTestSuite) TearDownTest() {
+	s.Nil(s.mock.ExpectationsWereMet())
+	s.mock.ExpectClose()
+	s.Nil(s.db.Close())
+}
+
+func (s *MigrateTestSuite) TestGivenPendingMigrationsThenTheyShouldBeAppliedInVersionOrder() {
+	fsys := fstest.MapFS{
+		"10_add_index.sql":    {Data: []byte("CREATE INDEX c")},
+		"2_add_column.sql":    {Data: []byte("ALTER TABLE b")},
+		"1_create_table.sql":  {Data: []byte("CREATE TABLE a")},
+		"not_a_migration.txt": {Data: []byte("ignored")},
+	}
+
+	s.expectLock()
+	s.mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	s.expectApplied(1, false)
+	s.expectApply("CREATE TABLE a", 1)
+	s.expectApplied(2, true)
+	s.expectApplied(10, false)
+	s.expectApply("CREATE INDEX c", 10)
+	s.expectUnlock()
+
+	s.Nil(postgres.Migrate(context.Background(), s.db, fsys))
+}
+
+func (s *MigrateTestSuite) TestGivenFailingMigrationThenItShouldRollBackAndStop() {
+	fsys := fstest.MapFS{
+		"1_create_table.sql": {Data: []byte("CREATE TABLE a")},
+		"2_add_column.sql":   {Data: []byte("ALTER TABLE b")},
+	}
+
+	s.expectLock()
+	s.mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	s.expectApplied(1, false)
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE a")).WillReturnError(sql.ErrConnDone)
+	s.mock.ExpectRollback()
+	// the lock is released even though the migration failed
+	s.expectUnlock()
+
+	err := postgres.Migrate(context.Background(), s.db, fsys)
+
+	s.ErrorIs(err, sql.ErrConnDone)
+	s.Contains(err.Error(), "1_create_table.sql")
+}
+
+func (s *MigrateTestSuite) TestGivenLockNotTakenThenNoMigrationShouldBeApplied() {
+	fsys := fstest.MapFS{"1_create_table.sql": {Data: []byte("CREATE TABLE a")}}
+
+	s.mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WillReturnError(sql.ErrConnDone)
+
+	err := postgres.Migrate(context.Background(), s.db, fsys)
+
+	s.ErrorIs(err, sql.ErrConnDone)
+}
+
+func (s *MigrateTestSuite) TestGivenInvalidFileNamesThenMigrateShouldFailBeforeTouchingTheDatabase() {
+	s.NotNil(postgres.Migrate(context.Background(), s.db, fstest.MapFS{"create_table.sql": {}}))
+	s.NotNil(postgres.Migrate(context.Background(), s.db, fstest.MapFS{"1_a.sql": {}, "01_b.sql": {}}))
+}
+
+func (s *MigrateTestSuite) TestGivenMigrationsThenLatestVersionShouldBeTheHighestOne() {
+	version, err := postgres.LatestVersion(fstest.MapFS{
+		"2_add_column.sql":    {},
+		"10_add_index.sql":    {},
+		"1_create_table.sql":  {},
+		"not_a_migration.txt": {},
+	})
+
+	s.Nil(err)
+	s.Equal(10, version)
+}
+
+func (s *MigrateTestSuite) TestGivenNoMigrationsThenLatestVersionShouldBeZero() {
+	version, err := postgres.LatestVersion(fstest.MapFS{})
+
+	s.Nil(err)
+	s.Equal(0, version)
+}
+
+func (s *MigrateTestSuite) TestGivenInvalidFileNameThenLatestVersionShouldFail() {
+	_, err := postgres.LatestVersion(fstest.MapFS{"create_table.sql": {}})
+
+	s.NotNil(err)
+}
+
+func (s *MigrateTestSuite) TestGivenAppliedMigrationsThenCurrentVersionShouldBeTheHighestOne() {
+	s.expectCurrentVersion().WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(5))
+
+	version, err := postgres.CurrentVersion(context.Background(), s.db)
+
+	s.Nil(err)
+	s.Equal(5, version)
+}
+
+func (s *MigrateTestSuite) TestGivenUnreadableSchemaVersionThenCurrentVersionShouldFail() {
+	s.expectCurrentVersion().WillReturnError(sql.ErrConnDone)
+
+	_, err := postgres.CurrentVersion(context.Background(), s.db)
+
+	s.ErrorIs(err, sql.ErrConnDone)
+}
+
+func (s *MigrateTestSuite) expectCurrentVersion() *sqlmock.ExpectedQuery {
+	return s.mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(version), 0) FROM schema_migrations"))
+}
+
+func (s *MigrateTestSuite) expectLock() {
+	s.mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (s *MigrateTestSuite) expectUnlock() {
+	s.mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (s *MigrateTestSuite) expectApplied(version int, applied bool) {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)")).
+		WithArgs(version).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(applied))
+}
+
+func (s *MigrateTestSuite) expectApply(query string, version int) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version) VALUES ($1)")).
+		WithArgs(version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+}
@@ -0,0 +1,68 @@
+package reqctx
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HeaderRequestID correlates the logs of a request across the services it
+// passes through.
+const HeaderRequestID = "X-Request-ID"
+
+type requestIDKey struct{}
+
+type loggerKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the id of the request ctx belongs to, an empty string
+// outside of requests such as in the workers.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the logger of the request ctx belongs to, which carries its
+// request id, and falls back to fallback outside of requests.
+func Logger(ctx context.Context, fallback *logrus.Logger) *logrus.Entry {
+	if logger, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok {
+		return logger
+	}
+
+	return logrus.NewEntry(fallback)
+}
+
+// Detach returns a context which carries the values of ctx, such as the
+// request id, the logger and the span, but is not cancelled with ctx. Work
+// which outlives the request or is shared with other requests runs on it.
+func Detach(ctx context.Context) context.Context {
+	return detached{parent: ctx}
+}
+
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
+
+func (d detached) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"github.com/pact-cdc-example/basket-service/pkg/postgres"
+)
+
+// Pinger is implemented by *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type pingChecker struct {
+	name string
+	db   Pinger
+}
+
+// NewPingChecker checks that db answers a ping.
+func NewPingChecker(name string, db Pinger) Checker {
+	return &pingChecker{name: name, db: db}
+}
+
+func (c *pingChecker) Name() string {
+	return c.name
+}
+
+func (c *pingChecker) Check(ctx context.Context) (string, error) {
+	return "", c.db.PingContext(ctx)
+}
+
+type httpChecker struct {
+	name       string
+	url        string
+	httpClient *http.Client
+}
+
+type NewHTTPCheckerOpts struct {
+	Name string
+	URL  string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// NewHTTPChecker checks that the api at URL is reachable. Any answer below
+// 500 counts, the api does not need a route for URL to be up.
+func NewHTTPChecker(opts *NewHTTPCheckerOpts) Checker {
+	c := &httpChecker{name: opts.Name, url: opts.URL, httpClient: opts.HTTPClient}
+
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
+	return c
+}
+
+func (c *httpChecker) Name() string {
+	return c.name
+}
+
+func (c *httpChecker) Check(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		return "", fmt.Errorf("%s answered with %d", c.url, res.StatusCode)
+	}
+
+	return fmt.Sprintf("status %d", res.StatusCode), nil
+}
+
+type migrationChecker struct {
+	name       string
+	db         *sql.DB
+	migrations fs.FS
+}
+
+type NewMigrationCheckerOpts struct {
+	Name       string
+	DB         *sql.DB
+	Migrations fs.FS
+}
+
+// NewMigrationChecker checks that the schema of DB is at the latest version
+// of Migrations, an instance must not serve an older schema than it expects.
+func NewMigrationChecker(opts *NewMigrationCheckerOpts) Checker {
+	return &migrationChecker{name: opts.Name, db: opts.DB, migrations: opts.Migrations}
+}
+
+func (c *migrationChecker) Name() string {
+	return c.name
+}
+
+func (c *migrationChecker) Check(ctx context.Context) (string, error) {
+	latest, err := postgres.LatestVersion(c.migrations)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := postgres.CurrentVersion(ctx, c.db)
+	if err != nil {
+		return "", err
+	}
+
+	detail := fmt.Sprintf("version %d", current)
+	if current < latest {
+		return detail, fmt.Errorf("schema is at version %d, expected %d", current, latest)
+	}
+
+	return detail, nil
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/pact-cdc-example/basket-service/pkg/reqctx"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const headerTraceParent = "traceparent"
+
+// NewErrorHandler returns the handler rendering the errors returned by the
+// handlers. Error bags are answered with the status registered for their
+// code, errors of fiber such as unknown routes keep their status, and any
+// other error is hidden behind the processing error. Messages are localized
+// to the Accept-Language of the request. Server errors are logged together
+// with their causes, which never reach the response, to l or to the standard
+// logger of logrus when l is nil.
+func NewErrorHandler(l *logrus.Logger) fiber.ErrorHandler {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+
+	return func(c *fiber.Ctx, err error) error {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return fiber.DefaultErrorHandler(c, fiberErr)
+		}
+
+		var bag cerr.Bag
+		if !errors.As(err, &bag) {
+			bag = cerr.Processing().Wrap(err)
+		}
+
+		status := bag.Status()
+		if status >= fiber.StatusInternalServerError {
+			reqctx.Logger(c.UserContext(), l).WithFields(logrus.Fields{
+				"path":  c.Path(),
+				"code":  bag.Code,
+				"class": cerr.ClassOf(bag).String(),
+			}).Errorf("request failed: %+v", bag)
+		}
+
+		locale := cerr.NegotiateLocale(c.Get(fiber.HeaderAcceptLanguage))
+		bag = cerr.Localize(bag, locale)
+
+		c.Status(status)
+		c.Vary(fiber.HeaderAcceptLanguage)
+		c.Set(fiber.HeaderContentLanguage, locale)
+
+		// The legacy shape is offered first, so only clients which ask for
+		// problem details explicitly get them.
+		if c.Accepts(fiber.MIMEApplicationJSON, cerr.ProblemContentType) != cerr.ProblemContentType {
+			return c.JSON(bag)
+		}
+
+		if err := c.JSON(bag.Problem(c.Path(), traceID(c))); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, cerr.ProblemContentType)
+
+		return nil
+	}
+}
+
+// serveNext runs the rest of the chain and renders the error it returns, so
+// the middlewares read the status the client gets from the response. The
+// error is rendered by the innermost middleware only, the ones around it get
+// no error back.
+func serveNext(c *fiber.Ctx) {
+	if err := c.Next(); err != nil {
+		if err = c.App().ErrorHandler(c, err); err != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+}
+
+// traceID returns the id of the trace the request belongs to. Without a
+// tracer provider it falls back to the W3C traceparent header of the
+// request, and returns an empty string when there is none.
+func traceID(c *fiber.Ctx) string {
+	if spanContext := trace.SpanContextFromContext(c.UserContext()); spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+
+	parts := strings.Split(c.Get(headerTraceParent), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+
+	return parts[1]
+}
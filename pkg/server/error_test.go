@@ -0,0 +1,191 @@
+package server_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/pact-cdc-example/basket-service/pkg/server"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+const conflictErrCode cerr.Code = 99001
+
+type ErrorHandlerTestSuite struct {
+	suite.Suite
+	app *fiber.App
+}
+
+func TestErrorHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ErrorHandlerTestSuite))
+}
+
+func (s *ErrorHandlerTestSuite) SetupTest() {
+	cerr.RegisterStatus(conflictErrCode, http.StatusConflict)
+
+	s.app = fiber.New(fiber.Config{ErrorHandler: server.NewErrorHandler(logrus.New())})
+	s.app.Get("/registered", func(c *fiber.Ctx) error {
+		return cerr.Bag{Code: conflictErrCode, Message: "conflict"}
+	})
+	s.app.Get("/unregistered", func(c *fiber.Ctx) error {
+		return cerr.Bag{Code: 99002, Message: "unknown"}
+	})
+	s.app.Get("/body-parser", func(c *fiber.Ctx) error {
+		return cerr.BodyParser()
+	})
+	s.app.Get("/wrapped", func(c *fiber.Ctx) error {
+		return cerr.Processing().Wrap(errors.New("pq: password authentication failed"))
+	})
+	s.app.Get("/plain", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenBagWithRegisteredCodeThenItShouldAnswerWithItsStatus() {
+	status, body := s.get("/registered")
+
+	s.Equal(http.StatusConflict, status)
+	s.JSONEq(`{"code":99001,"message":"conflict"}`, body)
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenBagWithUnregisteredCodeThenItShouldAnswerWithInternalServerError() {
+	status, body := s.get("/unregistered")
+
+	s.Equal(http.StatusInternalServerError, status)
+	s.JSONEq(`{"code":99002,"message":"unknown"}`, body)
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenBodyParserErrorThenItShouldAnswerWithBadRequest() {
+	status, _ := s.get("/body-parser")
+
+	s.Equal(http.StatusBadRequest, status)
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenPlainErrorThenItShouldHideItBehindProcessingError() {
+	status, body := s.get("/plain")
+
+	s.Equal(http.StatusInternalServerError, status)
+	s.NotContains(body, "connection refused")
+	s.Contains(body, "10002")
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenProblemDetailsAcceptedThenItShouldAnswerWithProblem() {
+	req := httptest.NewRequest(http.MethodGet, "/registered", nil)
+	req.Header.Set(fiber.HeaderAccept, cerr.ProblemContentType)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	res, body := s.do(req)
+
+	s.Equal(http.StatusConflict, res.StatusCode)
+	s.Equal(cerr.ProblemContentType, res.Header.Get(fiber.HeaderContentType))
+	s.JSONEq(`{
+		"type": "urn:pact-cdc-example:basket-service:error:99001",
+		"title": "Conflict",
+		"status": 409,
+		"detail": "conflict",
+		"instance": "/registered",
+		"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"code": 99001
+	}`, body)
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenJSONPreferredOverProblemDetailsThenItShouldAnswerWithBag() {
+	req := httptest.NewRequest(http.MethodGet, "/registered", nil)
+	req.Header.Set(fiber.HeaderAccept, "application/problem+json;q=0.5, application/json")
+
+	res, body := s.do(req)
+
+	s.Equal(fiber.MIMEApplicationJSON, res.Header.Get(fiber.HeaderContentType))
+	s.JSONEq(`{"code":99001,"message":"conflict"}`, body)
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenAnyMediaTypeAcceptedThenItShouldAnswerWithBag() {
+	req := httptest.NewRequest(http.MethodGet, "/registered", nil)
+	req.Header.Set(fiber.HeaderAccept, "*/*")
+
+	res, body := s.do(req)
+
+	s.Equal(fiber.MIMEApplicationJSON, res.Header.Get(fiber.HeaderContentType))
+	s.JSONEq(`{"code":99001,"message":"conflict"}`, body)
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenWrappedCauseThenItShouldNotLeakIntoTheResponse() {
+	status, body := s.get("/wrapped")
+
+	s.Equal(http.StatusInternalServerError, status)
+	s.JSONEq(`{"code":10002,"message":"Error occurred when processing the request."}`, body)
+
+	req := httptest.NewRequest(http.MethodGet, "/wrapped", nil)
+	req.Header.Set(fiber.HeaderAccept, cerr.ProblemContentType)
+	_, body = s.do(req)
+
+	s.NotContains(body, "pq:")
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenAcceptLanguageThenTheMessageShouldBeLocalized() {
+	req := httptest.NewRequest(http.MethodGet, "/body-parser", nil)
+	req.Header.Set(fiber.HeaderAcceptLanguage, "tr-TR, tr;q=0.9")
+
+	res, body := s.do(req)
+
+	s.Equal("tr", res.Header.Get(fiber.HeaderContentLanguage))
+	s.JSONEq(`{"code":10001,"message":"İstek gövdesi okunamadı."}`, body)
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenUnknownRouteThenItShouldKeepTheStatusOfFiber() {
+	status, _ := s.get("/unknown")
+
+	s.Equal(http.StatusNotFound, status)
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenNoLoggerThenServerErrorShouldBeLoggedToStandardLogger() {
+	s.app = fiber.New(fiber.Config{ErrorHandler: server.NewErrorHandler(nil)})
+	s.app.Get("/plain", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+
+	status, _ := s.get("/plain")
+
+	s.Equal(http.StatusInternalServerError, status)
+}
+
+func (s *ErrorHandlerTestSuite) TestGivenMiddlewaresAroundFailingHandlerThenErrorShouldBeRenderedOnce() {
+	rendered := 0
+	errorHandler := server.NewErrorHandler(logrus.New())
+	s.app = fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+		rendered++
+		return errorHandler(c, err)
+	}})
+	s.app.Use(server.NewRequestTracing())
+	s.app.Use(server.NewRequestLogger(logrus.New()))
+	s.app.Use(server.NewRequestMetrics())
+	s.app.Get("/plain", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+
+	status, _ := s.get("/plain")
+
+	s.Equal(http.StatusInternalServerError, status)
+	s.Equal(1, rendered)
+}
+
+func (s *ErrorHandlerTestSuite) get(path string) (int, string) {
+	res, body := s.do(httptest.NewRequest(http.MethodGet, path, nil))
+	return res.StatusCode, body
+}
+
+func (s *ErrorHandlerTestSuite) do(req *http.Request) (*http.Response, string) {
+	res, err := s.app.Test(req)
+	s.Require().Nil(err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	s.Require().Nil(err)
+
+	return res, string(body)
+}
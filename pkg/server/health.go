@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCheckTimeout = 2 * time.Second
+	defaultHealthTTL    = 5 * time.Second
+)
+
+// Status is the state of a single check or of the whole service.
+type Status string
+
+const (
+	StatusUp Status = "up"
+	// StatusDegraded is reported when only checks which are not critical
+	// fail, the service still serves most requests.
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Checker probes one dependency of the service. The detail is shown in the
+// report, e.g. the schema version a database is at.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) (detail string, err error)
+}
+
+// Check registers a checker with the health of the service. A failing
+// critical check takes the service down, any other one degrades it.
+type Check struct {
+	Checker  Checker
+	Critical bool
+	// Timeout bounds a single run of the checker, NewHealthOpts.Timeout is
+	// used when it is zero.
+	Timeout time.Duration
+}
+
+// CheckResult is the outcome of a check as it is reported. The error of a
+// failed check is only logged, the report is served on a public port and
+// must not tell the internals of the dependencies.
+type CheckResult struct {
+	Status    Status `json:"status"`
+	Critical  bool   `json:"critical"`
+	Detail    string `json:"detail,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+type Report struct {
+	Status    Status                 `json:"status"`
+	Draining  bool                   `json:"draining,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+type Health interface {
+	// Report runs the checks, or returns the report of the last run while
+	// it is not older than the cache ttl.
+	Report(ctx context.Context) Report
+	// Drain marks the service as shutting down, from then on it is reported
+	// down without running the checks.
+	Drain()
+}
+
+type health struct {
+	checks  []Check
+	timeout time.Duration
+	ttl     time.Duration
+	logger  *logrus.Logger
+
+	mu       sync.Mutex
+	report   *Report
+	draining bool
+}
+
+type NewHealthOpts struct {
+	Checks []Check
+	// Timeout is the default timeout of the checks.
+	Timeout time.Duration
+	// CacheTTL is how long a report is served before the checks run again,
+	// so probes of many instances can not flood the dependencies.
+	CacheTTL time.Duration
+	L        *logrus.Logger
+}
+
+func NewHealth(opts *NewHealthOpts) Health {
+	h := &health{
+		checks:  opts.Checks,
+		timeout: opts.Timeout,
+		ttl:     opts.CacheTTL,
+		logger:  opts.L,
+	}
+
+	if h.timeout <= 0 {
+		h.timeout = defaultCheckTimeout
+	}
+
+	if h.ttl <= 0 {
+		h.ttl = defaultHealthTTL
+	}
+
+	if h.logger == nil {
+		h.logger = logrus.StandardLogger()
+	}
+
+	return h
+}
+
+func (h *health) Report(ctx context.Context) Report {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.draining {
+		return Report{Status: StatusDown, Draining: true, CheckedAt: time.Now()}
+	}
+
+	if h.report != nil && time.Since(h.report.CheckedAt) < h.ttl {
+		return *h.report
+	}
+
+	report := h.run(ctx)
+	h.report = &report
+
+	return report
+}
+
+func (h *health) Drain() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.draining = true
+}
+
+// run runs the checks concurrently, so the report takes as long as the
+// slowest check and not as long as all of them.
+func (h *health) run(ctx context.Context) Report {
+	results := make([]CheckResult, len(h.checks))
+	errs := make([]error, len(h.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i], errs[i] = h.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusUp,
+		CheckedAt: time.Now(),
+		Checks:    make(map[string]CheckResult, len(h.checks)),
+	}
+
+	for i, check := range h.checks {
+		result := results[i]
+		report.Checks[check.Checker.Name()] = result
+
+		if result.Status == StatusUp {
+			continue
+		}
+
+		h.logger.WithField("check", check.Checker.Name()).Warnf("health check failed: %v", errs[i])
+
+		if check.Critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+func (h *health) runCheck(ctx context.Context, check Check) (CheckResult, error) {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = h.timeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check.Checker.Check(ctx)
+
+	result := CheckResult{
+		Status:    StatusUp,
+		Critical:  check.Critical,
+		Detail:    detail,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+	}
+
+	return result, err
+}
+
+// NewHealthHandler answers with the report of h, a down service with 503 so
+// load balancers stop routing to it.
+func NewHealthHandler(h Health) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// the report is cached for other probes too, a probe which gives up
+		// must not cancel the checks.
+		report := h.Report(context.Background())
+
+		status := fiber.StatusOK
+		if report.Status == StatusDown {
+			status = fiber.StatusServiceUnavailable
+		}
+
+		c.Set(fiber.HeaderCacheControl, "no-store")
+
+		return c.Status(status).JSON(report)
+	}
+}
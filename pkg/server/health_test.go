@@ -0,0 +1,242 @@
+package server_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/basket-service/pkg/server"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeChecker struct {
+	name  string
+	err   error
+	block bool
+	calls int32
+}
+
+func (c *fakeChecker) Name() string {
+	return c.name
+}
+
+func (c *fakeChecker) Check(ctx context.Context) (string, error) {
+	atomic.AddInt32(&c.calls, 1)
+
+	if c.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+
+	return "ok", c.err
+}
+
+type HealthTestSuite struct {
+	suite.Suite
+	logger *logrus.Logger
+	hook   *test.Hook
+}
+
+func TestHealthTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
+
+func (s *HealthTestSuite) SetupTest() {
+	s.logger, s.hook = test.NewNullLogger()
+}
+
+func (s *HealthTestSuite) TestGivenAllChecksUpThenReportShouldBeUp() {
+	health := server.NewHealth(&server.NewHealthOpts{
+		Checks: []server.Check{{Checker: &fakeChecker{name: "postgres"}, Critical: true}},
+		L:      s.logger,
+	})
+
+	report := health.Report(context.Background())
+
+	s.Equal(server.StatusUp, report.Status)
+	s.Equal(server.StatusUp, report.Checks["postgres"].Status)
+	s.Equal("ok", report.Checks["postgres"].Detail)
+}
+
+func (s *HealthTestSuite) TestGivenFailingCheckThenStatusShouldDependOnWhetherItIsCritical() {
+	degraded := server.NewHealth(&server.NewHealthOpts{
+		Checks: []server.Check{
+			{Checker: &fakeChecker{name: "postgres"}, Critical: true},
+			{Checker: &fakeChecker{name: "stock-api", err: errors.New("connection refused")}},
+		},
+		L: s.logger,
+	}).Report(context.Background())
+
+	s.Equal(server.StatusDegraded, degraded.Status)
+	s.Equal(server.StatusDown, degraded.Checks["stock-api"].Status)
+
+	entry := s.hook.LastEntry()
+	s.Require().NotNil(entry)
+	s.Equal("stock-api", entry.Data["check"])
+	s.Equal("health check failed: connection refused", entry.Message)
+
+	down := server.NewHealth(&server.NewHealthOpts{
+		Checks: []server.Check{
+			{Checker: &fakeChecker{name: "postgres", err: errors.New("connection refused")}, Critical: true},
+			{Checker: &fakeChecker{name: "stock-api", err: errors.New("connection refused")}},
+		},
+		L: s.logger,
+	}).Report(context.Background())
+
+	s.Equal(server.StatusDown, down.Status)
+}
+
+func (s *HealthTestSuite) TestGivenHangingCheckThenItShouldTimeOut() {
+	health := server.NewHealth(&server.NewHealthOpts{
+		Checks: []server.Check{
+			{Checker: &fakeChecker{name: "postgres", block: true}, Critical: true, Timeout: 10 * time.Millisecond},
+		},
+		Timeout: time.Minute,
+		L:       s.logger,
+	})
+
+	start := time.Now()
+	report := health.Report(context.Background())
+
+	s.Less(time.Since(start), time.Second)
+	s.Equal(server.StatusDown, report.Status)
+	s.Require().NotNil(s.hook.LastEntry())
+	s.Contains(s.hook.LastEntry().Message, context.DeadlineExceeded.Error())
+}
+
+func (s *HealthTestSuite) TestGivenFreshReportThenChecksShouldNotRunAgain() {
+	checker := &fakeChecker{name: "postgres"}
+	health := server.NewHealth(&server.NewHealthOpts{
+		Checks:   []server.Check{{Checker: checker}},
+		CacheTTL: time.Minute,
+		L:        s.logger,
+	})
+
+	health.Report(context.Background())
+	health.Report(context.Background())
+
+	s.Equal(int32(1), atomic.LoadInt32(&checker.calls))
+}
+
+func (s *HealthTestSuite) TestGivenDrainingServiceThenItShouldBeReportedDown() {
+	checker := &fakeChecker{name: "postgres"}
+	health := server.NewHealth(&server.NewHealthOpts{
+		Checks: []server.Check{{Checker: checker, Critical: true}},
+		L:      s.logger,
+	})
+
+	s.Equal(server.StatusUp, health.Report(context.Background()).Status)
+
+	health.Drain()
+	report := health.Report(context.Background())
+
+	s.Equal(server.StatusDown, report.Status)
+	s.True(report.Draining)
+	s.Equal(int32(1), atomic.LoadInt32(&checker.calls))
+}
+
+func (s *HealthTestSuite) TestGivenDownServiceThenHandlerShouldAnswerWithServiceUnavailable() {
+	app := fiber.New()
+	app.Get("/readiness", server.NewHealthHandler(server.NewHealth(&server.NewHealthOpts{
+		Checks: []server.Check{
+			{Checker: &fakeChecker{name: "postgres", err: errors.New("connection refused")}, Critical: true},
+		},
+		L: s.logger,
+	})))
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/readiness", nil))
+	s.Require().Nil(err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	s.Require().Nil(err)
+
+	var report server.Report
+	s.Require().Nil(json.Unmarshal(body, &report))
+
+	s.Equal(http.StatusServiceUnavailable, res.StatusCode)
+	s.Equal(server.StatusDown, report.Status)
+	s.Equal(server.StatusDown, report.Checks["postgres"].Status)
+	s.NotContains(string(body), "connection refused")
+}
+
+func (s *HealthTestSuite) TestGivenReachableAPIThenHTTPCheckerShouldPass() {
+	api := httptest.NewServer(http.NotFoundHandler())
+	defer api.Close()
+
+	detail, err := server.NewHTTPChecker(&server.NewHTTPCheckerOpts{
+		Name: "stock-api", URL: api.URL,
+	}).Check(context.Background())
+
+	s.Nil(err)
+	s.Equal("status 404", detail)
+}
+
+func (s *HealthTestSuite) TestGivenFailingAPIThenHTTPCheckerShouldFail() {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer api.Close()
+
+	_, err := server.NewHTTPChecker(&server.NewHTTPCheckerOpts{
+		Name: "stock-api", URL: api.URL,
+	}).Check(context.Background())
+
+	s.NotNil(err)
+}
+
+func (s *HealthTestSuite) TestGivenSchemaAtLatestVersionThenMigrationCheckerShouldPass() {
+	db, mock := s.schemaAtVersion(2)
+
+	detail, err := s.newMigrationChecker(db).Check(context.Background())
+
+	s.Nil(err)
+	s.Equal("version 2", detail)
+	s.Nil(mock.ExpectationsWereMet())
+}
+
+func (s *HealthTestSuite) TestGivenSchemaBehindMigrationsThenMigrationCheckerShouldFail() {
+	db, mock := s.schemaAtVersion(1)
+
+	detail, err := s.newMigrationChecker(db).Check(context.Background())
+
+	s.NotNil(err)
+	s.Equal("version 1", detail)
+	s.Nil(mock.ExpectationsWereMet())
+}
+
+func (s *HealthTestSuite) newMigrationChecker(db *sql.DB) server.Checker {
+	return server.NewMigrationChecker(&server.NewMigrationCheckerOpts{
+		Name: "migrations",
+		DB:   db,
+		Migrations: fstest.MapFS{
+			"1_create_table.sql": {Data: []byte("CREATE TABLE a")},
+			"2_add_column.sql":   {Data: []byte("ALTER TABLE a")},
+		},
+	})
+}
+
+// schemaAtVersion returns a database whose schema_migrations is at version.
+func (s *HealthTestSuite) schemaAtVersion(version int) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	s.Require().Nil(err)
+	s.T().Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(version), 0) FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(version))
+
+	return db, mock
+}
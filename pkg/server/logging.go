@@ -0,0 +1,82 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/basket-service/pkg/reqctx"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxRequestIDLength = 128
+
+// NewRequestLogger assigns every request an id, or keeps the one the caller
+// sent, and puts a logger carrying it into the user context of the request.
+// Once the request is answered it logs the request with its status and
+// latency, to l or to the standard logger of logrus when l is nil.
+func NewRequestLogger(l *logrus.Logger) fiber.Handler {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		requestID := c.Get(reqctx.HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set(reqctx.HeaderRequestID, requestID)
+
+		logger := l.WithField("request_id", requestID)
+		if spanContext := trace.SpanContextFromContext(c.UserContext()); spanContext.HasTraceID() {
+			logger = logger.WithField("trace_id", spanContext.TraceID().String())
+		}
+		ctx := reqctx.WithRequestID(c.UserContext(), requestID)
+		c.SetUserContext(reqctx.WithLogger(ctx, logger))
+
+		serveNext(c)
+
+		status := c.Response().StatusCode()
+		fields := logrus.Fields{
+			"method":     utils.CopyString(c.Method()),
+			"path":       utils.CopyString(c.Path()),
+			"status":     status,
+			"latency_ms": time.Since(start).Milliseconds(),
+		}
+		if basketID := c.Params("basket_id"); basketID != "" {
+			fields["basket_id"] = utils.CopyString(basketID)
+		}
+
+		entry := logger.WithFields(fields)
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			entry.Error("request failed")
+		case status >= fiber.StatusBadRequest:
+			entry.Warn("request rejected")
+		default:
+			entry.Info("request served")
+		}
+
+		return nil
+	}
+}
+
+// validRequestID accepts the ids of callers which are short printable ascii,
+// so they can not forge log lines.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,108 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/pact-cdc-example/basket-service/pkg/reqctx"
+	"github.com/pact-cdc-example/basket-service/pkg/server"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestLoggerTestSuite struct {
+	suite.Suite
+	app  *fiber.App
+	hook *test.Hook
+	// seenRequestID is the request id the handler found in its context.
+	seenRequestID string
+}
+
+func TestRequestLoggerTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestLoggerTestSuite))
+}
+
+func (s *RequestLoggerTestSuite) SetupTest() {
+	logger, hook := test.NewNullLogger()
+	s.hook = hook
+	s.seenRequestID = ""
+
+	s.app = fiber.New(fiber.Config{ErrorHandler: server.NewErrorHandler(logger)})
+	s.app.Use(server.NewRequestLogger(logger))
+	s.app.Get("/baskets/:basket_id", func(c *fiber.Ctx) error {
+		s.seenRequestID = reqctx.RequestID(c.UserContext())
+		reqctx.Logger(c.UserContext(), logrus.New()).Info("handling")
+		return c.SendString("ok")
+	})
+	s.app.Post("/baskets/:basket_id", func(c *fiber.Ctx) error {
+		return cerr.BodyParser()
+	})
+}
+
+func (s *RequestLoggerTestSuite) TestGivenNoRequestIDThenItShouldAssignOne() {
+	res := s.get("/baskets/b1", "")
+
+	requestID := res.Header.Get(reqctx.HeaderRequestID)
+	s.NotEmpty(requestID)
+	s.Equal(requestID, s.seenRequestID)
+
+	s.Require().Len(s.hook.AllEntries(), 2)
+	s.Equal(requestID, s.hook.AllEntries()[0].Data["request_id"])
+}
+
+func (s *RequestLoggerTestSuite) TestGivenRequestIDThenItShouldBePropagated() {
+	res := s.get("/baskets/b1", "checkout-42")
+
+	s.Equal("checkout-42", res.Header.Get(reqctx.HeaderRequestID))
+	s.Equal("checkout-42", s.seenRequestID)
+}
+
+func (s *RequestLoggerTestSuite) TestGivenForgedRequestIDThenItShouldBeReplaced() {
+	res := s.get("/baskets/b1", "id\" level=error msg=forged")
+
+	s.NotEqual("id\" level=error msg=forged", res.Header.Get(reqctx.HeaderRequestID))
+}
+
+func (s *RequestLoggerTestSuite) TestGivenServedRequestThenItShouldBeLogged() {
+	s.get("/baskets/b1", "r1")
+
+	entry := s.hook.LastEntry()
+	s.Equal(logrus.InfoLevel, entry.Level)
+	s.Equal("r1", entry.Data["request_id"])
+	s.Equal(http.MethodGet, entry.Data["method"])
+	s.Equal("/baskets/b1", entry.Data["path"])
+	s.Equal(http.StatusOK, entry.Data["status"])
+	s.Equal("b1", entry.Data["basket_id"])
+	s.Contains(entry.Data, "latency_ms")
+}
+
+func (s *RequestLoggerTestSuite) TestGivenFailedRequestThenTheRenderedStatusShouldBeLogged() {
+	res := s.do(http.MethodPost, "/baskets/b2", "r2")
+
+	s.Equal(http.StatusBadRequest, res.StatusCode)
+
+	entry := s.hook.LastEntry()
+	s.Equal(logrus.WarnLevel, entry.Level)
+	s.Equal(http.StatusBadRequest, entry.Data["status"])
+	s.Equal("b2", entry.Data["basket_id"])
+}
+
+func (s *RequestLoggerTestSuite) get(path string, requestID string) *http.Response {
+	return s.do(http.MethodGet, path, requestID)
+}
+
+func (s *RequestLoggerTestSuite) do(method string, path string, requestID string) *http.Response {
+	req := httptest.NewRequest(method, path, nil)
+	if requestID != "" {
+		req.Header.Set(reqctx.HeaderRequestID, requestID)
+	}
+
+	res, err := s.app.Test(req)
+	s.Require().Nil(err)
+
+	return res
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	metricsPath    = "/metrics"
+	unmatchedRoute = "unmatched"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Requests served by route, method and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the requests by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// NewRequestMetrics counts the requests and observes their latency by the
+// route they matched, so ids in paths do not multiply the series. Errors
+// are counted by the 4xx and 5xx statuses.
+func NewRequestMetrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Path() == metricsPath {
+			return c.Next()
+		}
+
+		start := time.Now()
+		middleware := c.Route()
+
+		serveNext(c)
+
+		// the route is still the one of the middleware when no other matched.
+		route := unmatchedRoute
+		if r := c.Route(); r != middleware {
+			route = r.Path
+		}
+
+		// fiber reuses the memory of the request strings, labels outlive it.
+		method := utils.CopyString(c.Method())
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Response().StatusCode())).Inc()
+		httpRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/pact-cdc-example/basket-service/pkg/server"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestMetricsTestSuite struct {
+	suite.Suite
+	app *fiber.App
+}
+
+func TestRequestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestMetricsTestSuite))
+}
+
+func (s *RequestMetricsTestSuite) SetupTest() {
+	s.app = fiber.New(fiber.Config{ErrorHandler: server.NewErrorHandler(logrus.New())})
+	s.app.Use(server.NewRequestMetrics())
+	s.app.Get("/metered/:basket_id", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	s.app.Post("/metered/:basket_id", func(c *fiber.Ctx) error {
+		return fiber.ErrConflict
+	})
+}
+
+func (s *RequestMetricsTestSuite) TestGivenRequestsThenTheyShouldBeCountedByRouteAndStatus() {
+	s.request(http.MethodGet, "/metered/b1")
+	s.request(http.MethodGet, "/metered/b2")
+	s.request(http.MethodPost, "/metered/b1")
+	s.request(http.MethodGet, "/not-metered")
+
+	exposition := s.scrape()
+
+	s.Contains(exposition,
+		`basket_service_http_requests_total{method="GET",route="/metered/:basket_id",status="200"} 2`)
+	s.Contains(exposition,
+		`basket_service_http_requests_total{method="POST",route="/metered/:basket_id",status="409"} 1`)
+	s.Contains(exposition,
+		`basket_service_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	s.Contains(exposition,
+		`basket_service_http_request_duration_seconds_count{method="GET",route="/metered/:basket_id"} 2`)
+	s.NotContains(exposition, "/metered/b1")
+}
+
+func (s *RequestMetricsTestSuite) request(method string, path string) {
+	res, err := s.app.Test(httptest.NewRequest(method, path, nil))
+	s.Require().Nil(err)
+	s.Require().Nil(res.Body.Close())
+}
+
+func (s *RequestMetricsTestSuite) scrape() string {
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Body)
+	s.Require().Nil(err)
+
+	return string(body)
+}
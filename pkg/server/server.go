@@ -1,22 +1,31 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/pact-cdc-example/basket-service/pkg/metrics"
+	"github.com/sirupsen/logrus"
 )
 
 type Server interface {
+	// Run serves until Shutdown is called.
 	Run() error
+	// Shutdown stops accepting connections and waits for the requests in
+	// flight until ctx is done.
+	Shutdown(ctx context.Context) error
 }
 
 type NewServerOpts struct {
 	Port string
+	// L defaults to the standard logger of logrus.
+	L *logrus.Logger
+	// Health backs the readiness and health endpoints, a service without
+	// checks is always up.
+	Health Health
 }
 
 type server struct {
@@ -29,9 +38,18 @@ type RouteHandler interface {
 }
 
 func New(opts *NewServerOpts, routeHandlers []RouteHandler) Server {
-	app := fiber.New()
+	if opts.L == nil {
+		opts.L = logrus.StandardLogger()
+	}
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: NewErrorHandler(opts.L),
+	})
 
 	app.Use(cors.New())
+	app.Use(NewRequestTracing())
+	app.Use(NewRequestLogger(opts.L))
+	app.Use(NewRequestMetrics())
 
 	apiGroup := app.Group("/api")
 	v1Group := apiGroup.Group("/v1")
@@ -40,6 +58,10 @@ func New(opts *NewServerOpts, routeHandlers []RouteHandler) Server {
 		handler.SetupRoutes(v1Group)
 	}
 
+	if opts.Health == nil {
+		opts.Health = NewHealth(&NewHealthOpts{L: opts.L})
+	}
+
 	s := &server{app: app, opts: opts}
 
 	s.addHealthCheckRoutes()
@@ -48,28 +70,26 @@ func New(opts *NewServerOpts, routeHandlers []RouteHandler) Server {
 }
 
 func (s *server) addHealthCheckRoutes() {
+	health := NewHealthHandler(s.opts.Health)
+
 	s.app.Get("/liveness", liveness)
-	s.app.Get("/readines", readiness)
+	s.app.Get("/readiness", health)
+	s.app.Get("/health", health)
+	// kept for the probes which still use the old misspelled path
+	s.app.Get("/readines", health)
+	s.app.Get(metricsPath, adaptor.HTTPHandler(metrics.Handler()))
 }
 
+// liveness checks no dependency, an instance is not restarted because
+// postgres is down.
 func liveness(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusOK)
 }
 
-func readiness(c *fiber.Ctx) error {
-	return c.SendStatus(fiber.StatusOK)
-}
-
 func (s *server) Run() error {
-	shutdownChan := make(chan os.Signal, 1)
-	signal.Notify(shutdownChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-shutdownChan
-		err := s.app.Shutdown()
-		if err != nil {
-			log.Println("Error on shutdown gracefully")
-		}
-	}()
-
 	return s.app.Listen(fmt.Sprintf(":%s", s.opts.Port))
 }
+
+func (s *server) Shutdown(ctx context.Context) error {
+	return s.app.ShutdownWithContext(ctx)
+}
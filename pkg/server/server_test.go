@@ -0,0 +1,63 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/basket-service/pkg/server"
+	"github.com/stretchr/testify/suite"
+)
+
+type failingRouteHandler struct{}
+
+func (failingRouteHandler) SetupRoutes(fr fiber.Router) {
+	fr.Get("/fail", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+}
+
+type ServerTestSuite struct {
+	suite.Suite
+}
+
+func TestServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
+
+func (s *ServerTestSuite) TestGivenNoLoggerThenServerErrorShouldBeAnswered() {
+	port := s.freePort()
+	app := server.New(&server.NewServerOpts{Port: port}, []server.RouteHandler{failingRouteHandler{}})
+
+	go func() {
+		_ = app.Run()
+	}()
+	defer app.Shutdown(context.Background())
+
+	url := fmt.Sprintf("http://127.0.0.1:%s/api/v1/fail", port)
+
+	var res *http.Response
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if res, err = http.Get(url); err == nil {
+			break
+		}
+	}
+	s.Require().Nil(err)
+	defer res.Body.Close()
+
+	s.Equal(http.StatusInternalServerError, res.StatusCode)
+}
+
+func (s *ServerTestSuite) freePort() string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().Nil(err)
+	defer l.Close()
+
+	return fmt.Sprint(l.Addr().(*net.TCPAddr).Port)
+}
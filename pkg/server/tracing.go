@@ -0,0 +1,76 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/pact-cdc-example/basket-service/pkg/server")
+
+// NewRequestTracing starts a server span for every request, as a child of
+// the span of the caller when it sent a W3C traceparent header. The span is
+// put into the user context, so the spans of the handlers join the trace.
+func NewRequestTracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		middleware := c.Route()
+		method := utils.CopyString(c.Method())
+
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), requestCarrier{c: c})
+		ctx, span := tracer.Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(method),
+				semconv.HTTPTarget(utils.CopyString(c.OriginalURL())),
+			),
+		)
+		defer span.End()
+
+		c.SetUserContext(ctx)
+
+		serveNext(c)
+
+		if r := c.Route(); r != middleware {
+			span.SetName(method + " " + r.Path)
+			span.SetAttributes(semconv.HTTPRoute(r.Path))
+		}
+
+		status := c.Response().StatusCode()
+		span.SetAttributes(semconv.HTTPStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		if basketID := c.Params("basket_id"); basketID != "" {
+			span.SetAttributes(attribute.String("basket.id", utils.CopyString(basketID)))
+		}
+
+		return nil
+	}
+}
+
+// requestCarrier reads the propagated trace context from the request
+// headers.
+type requestCarrier struct {
+	c *fiber.Ctx
+}
+
+func (rc requestCarrier) Get(key string) string {
+	return rc.c.Get(key)
+}
+
+func (rc requestCarrier) Set(key string, value string) {
+	rc.c.Request().Header.Set(key, value)
+}
+
+func (rc requestCarrier) Keys() []string {
+	var keys []string
+	rc.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+
+	return keys
+}
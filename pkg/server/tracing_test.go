@@ -0,0 +1,108 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/basket-service/pkg/cerr"
+	"github.com/pact-cdc-example/basket-service/pkg/server"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const givenTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+type RequestTracingTestSuite struct {
+	suite.Suite
+	app      *fiber.App
+	exporter *tracetest.InMemoryExporter
+	// handlerTraceID is the trace id the handler found in its context.
+	handlerTraceID string
+}
+
+func TestRequestTracingTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestTracingTestSuite))
+}
+
+// SetupSuite installs the provider once, the tracers of the packages keep
+// the first provider installed globally.
+func (s *RequestTracingTestSuite) SetupSuite() {
+	s.exporter = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(s.exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+func (s *RequestTracingTestSuite) SetupTest() {
+	s.exporter.Reset()
+
+	s.app = fiber.New(fiber.Config{ErrorHandler: server.NewErrorHandler(logrus.New())})
+	s.app.Use(server.NewRequestTracing())
+	s.app.Get("/baskets/:basket_id", func(c *fiber.Ctx) error {
+		s.handlerTraceID = trace.SpanContextFromContext(c.UserContext()).TraceID().String()
+		return c.SendString("ok")
+	})
+	s.app.Post("/baskets/:basket_id", func(c *fiber.Ctx) error {
+		return cerr.Processing()
+	})
+}
+
+func (s *RequestTracingTestSuite) TestGivenTraceParentThenTheRequestSpanShouldJoinTheTrace() {
+	s.request(http.MethodGet, givenTraceParent, "")
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+
+	span := spans[0]
+	s.Equal("GET /baskets/:basket_id", span.Name)
+	s.Equal(trace.SpanKindServer, span.SpanKind)
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	s.Equal("00f067aa0ba902b7", span.Parent.SpanID().String())
+	s.True(span.Parent.IsRemote())
+	s.Equal(span.SpanContext.TraceID().String(), s.handlerTraceID)
+	s.Contains(span.Attributes, attribute.Int("http.status_code", http.StatusOK))
+	s.Contains(span.Attributes, attribute.String("basket.id", "b1"))
+}
+
+func (s *RequestTracingTestSuite) TestGivenNoTraceParentThenANewTraceShouldBeStarted() {
+	s.request(http.MethodGet, "", "")
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	s.True(spans[0].SpanContext.HasTraceID())
+	s.False(spans[0].Parent.IsValid())
+}
+
+func (s *RequestTracingTestSuite) TestGivenServerErrorThenTheSpanShouldBeMarkedAndItsTraceReported() {
+	res := s.request(http.MethodPost, givenTraceParent, cerr.ProblemContentType)
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Equal(codes.Error, spans[0].Status.Code)
+	s.Contains(spans[0].Attributes, attribute.Int("http.status_code", http.StatusInternalServerError))
+	s.Equal(http.StatusInternalServerError, res.StatusCode)
+}
+
+func (s *RequestTracingTestSuite) request(method string, traceParent string, accept string) *http.Response {
+	req := httptest.NewRequest(method, "/baskets/b1", nil)
+	if traceParent != "" {
+		req.Header.Set("traceparent", traceParent)
+	}
+	if accept != "" {
+		req.Header.Set(fiber.HeaderAccept, accept)
+	}
+
+	res, err := s.app.Test(req)
+	s.Require().Nil(err)
+	s.Require().Nil(res.Body.Close())
+
+	return res
+}
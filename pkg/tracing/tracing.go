@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exporters the traces can be sent with
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+)
+
+type NewOpts struct {
+	// Exporter is one of otlp, stdout and none, none is the default.
+	Exporter string
+	// Endpoint is the host and port of the otlp http receiver, the
+	// OTEL_EXPORTER_OTLP_* environment variables are used when it is empty.
+	Endpoint    string
+	Insecure    bool
+	ServiceName string
+	// SampleRatio is the ratio of the traces started here which are
+	// sampled, zero samples all of them. Traces started by callers follow
+	// their decision.
+	SampleRatio float64
+}
+
+// Shutdown flushes the spans which are not exported yet.
+type Shutdown func(ctx context.Context) error
+
+// New installs the global tracer provider and the W3C trace context
+// propagator. With the none exporter spans are still created, so trace ids
+// are propagated, but they are not exported.
+func New(opts *NewOpts) (Shutdown, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(opts.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio(opts.SampleRatio)))),
+	}
+	if exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(opts *NewOpts) (sdktrace.SpanExporter, error) {
+	switch opts.Exporter {
+	case ExporterOTLP:
+		var clientOpts []otlptracehttp.Option
+		if opts.Endpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), clientOpts...)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterNone, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+}
+
+func sampleRatio(ratio float64) float64 {
+	if ratio <= 0 || ratio > 1 {
+		return 1
+	}
+
+	return ratio
+}
+
+// End records err on span before ending it, spans of failed operations are
+// marked as errors.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs periodic workers until it is stopped.
+type Group interface {
+	Start()
+	// Stop stops the workers from starting new jobs and waits until their
+	// running jobs return. The running jobs are cancelled only when ctx is
+	// done before they return.
+	Stop(ctx context.Context) error
+}
+
+type group struct {
+	workers []Periodic
+	// stop ends the ticking of the workers, ctx is the context of their jobs.
+	stop     chan struct{}
+	stopOnce sync.Once
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewGroup(workers ...Periodic) Group {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &group{workers: workers, stop: make(chan struct{}), ctx: ctx, cancel: cancel}
+}
+
+func (g *group) Start() {
+	for _, w := range g.workers {
+		g.wg.Add(1)
+		go func(w Periodic) {
+			defer g.wg.Done()
+			w.Run(g.ctx, g.stop)
+		}(w)
+	}
+}
+
+func (g *group) Stop(ctx context.Context) error {
+	g.stopOnce.Do(func() { close(g.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		g.cancel()
+		return nil
+	case <-ctx.Done():
+		g.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,103 @@
+package worker_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/pkg/worker"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type GroupTestSuite struct {
+	suite.Suite
+	logger *logrus.Logger
+}
+
+func TestGroupTestSuite(t *testing.T) {
+	suite.Run(t, new(GroupTestSuite))
+}
+
+func (s *GroupTestSuite) SetupTest() {
+	s.logger = logrus.New()
+	s.logger.SetLevel(logrus.PanicLevel)
+}
+
+func (s *GroupTestSuite) TestGivenRunningJobThenStopShouldLetItFinish() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	jobErr := make(chan error, 1)
+
+	group := worker.NewGroup(s.periodic(func(ctx context.Context) error {
+		close(started)
+		<-release
+		jobErr <- ctx.Err()
+		return nil
+	}))
+	group.Start()
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- group.Stop(context.Background())
+	}()
+
+	select {
+	case <-stopped:
+		s.FailNow("stop returned while the job was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+
+	s.Nil(<-jobErr)
+	s.Nil(<-stopped)
+}
+
+func (s *GroupTestSuite) TestGivenJobOutlastingStopThenItShouldBeCancelled() {
+	started := make(chan struct{})
+	jobErr := make(chan error, 1)
+
+	group := worker.NewGroup(s.periodic(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		jobErr <- ctx.Err()
+		return nil
+	}))
+	group.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	s.ErrorIs(group.Stop(ctx), context.DeadlineExceeded)
+	s.ErrorIs(<-jobErr, context.Canceled)
+}
+
+func (s *GroupTestSuite) TestGivenStoppedGroupThenNoJobShouldRunAnymore() {
+	var runs int32
+	group := worker.NewGroup(s.periodic(func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}))
+	group.Start()
+
+	s.Eventually(func() bool { return atomic.LoadInt32(&runs) > 0 }, time.Second, time.Millisecond)
+	s.Require().Nil(group.Stop(context.Background()))
+
+	stoppedAt := atomic.LoadInt32(&runs)
+	time.Sleep(20 * time.Millisecond)
+
+	s.Equal(stoppedAt, atomic.LoadInt32(&runs))
+}
+
+func (s *GroupTestSuite) periodic(job worker.Job) worker.Periodic {
+	return worker.NewPeriodic(&worker.NewPeriodicOpts{
+		Name:     "job",
+		Interval: time.Millisecond,
+		Job:      job,
+		L:        s.logger,
+	})
+}
@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultInterval is used when no interval is configured, time.NewTicker
+// does not accept a zero interval.
+const defaultInterval = time.Minute
+
+// Job is a unit of background work, an error is logged and the job is run
+// again on the next tick.
+type Job func(ctx context.Context) error
+
+type Periodic interface {
+	// Run calls the job with ctx on every interval until stop is closed or
+	// ctx is done. A job which is running when stop is closed is not
+	// interrupted.
+	Run(ctx context.Context, stop <-chan struct{})
+}
+
+type periodic struct {
+	name     string
+	interval time.Duration
+	job      Job
+	logger   *logrus.Logger
+}
+
+type NewPeriodicOpts struct {
+	Name     string
+	Interval time.Duration
+	Job      Job
+	L        *logrus.Logger
+}
+
+func NewPeriodic(opts *NewPeriodicOpts) Periodic {
+	p := &periodic{
+		name:     opts.Name,
+		interval: opts.Interval,
+		job:      opts.Job,
+		logger:   opts.L,
+	}
+
+	if p.interval <= 0 {
+		p.logger.WithField("worker", p.name).Warnf("no interval is configured, running every %s", defaultInterval)
+		p.interval = defaultInterval
+	}
+
+	return p
+}
+
+func (p *periodic) Run(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// select picks at random when both are ready, no job is started
+			// once the worker is stopped.
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			if err := p.job(ctx); err != nil {
+				p.logger.WithField("worker", p.name).Errorf("job failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/basket-service/pkg/worker"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type PeriodicTestSuite struct {
+	suite.Suite
+	logger *logrus.Logger
+}
+
+func TestPeriodicTestSuite(t *testing.T) {
+	suite.Run(t, new(PeriodicTestSuite))
+}
+
+func (s *PeriodicTestSuite) SetupTest() {
+	s.logger = logrus.New()
+	s.logger.SetLevel(logrus.PanicLevel)
+}
+
+func (s *PeriodicTestSuite) TestGivenNoIntervalThenItShouldRunWithoutPanicking() {
+	periodic := worker.NewPeriodic(&worker.NewPeriodicOpts{
+		Name: "job",
+		Job:  func(context.Context) error { return nil },
+		L:    s.logger,
+	})
+
+	stop := make(chan struct{})
+	close(stop)
+
+	s.NotPanics(func() { periodic.Run(context.Background(), stop) })
+}
+
+func (s *PeriodicTestSuite) TestGivenIntervalThenJobShouldRunOnEveryTick() {
+	runs := make(chan struct{})
+	periodic := worker.NewPeriodic(&worker.NewPeriodicOpts{
+		Name:     "job",
+		Interval: time.Millisecond,
+		Job: func(ctx context.Context) error {
+			select {
+			case runs <- struct{}{}:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+		L: s.logger,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		periodic.Run(ctx, nil)
+		close(done)
+	}()
+
+	<-runs
+	<-runs
+	cancel()
+	<-done
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pact-cdc-example/basket-service/config"
+	"github.com/pact-cdc-example/basket-service/pkg/pactstub"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	providerProductService = "ProductService"
+	providerStockService   = "StockService"
+)
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// runStubs serves the interactions this service recorded as a consumer on the
+// ports of the external urls, so the service runs without its providers.
+func runStubs(c config.Manager, args []string) error {
+	flags := flag.NewFlagSet("stubs", flag.ExitOnError)
+	pattern := flags.String("pacts", "app/*/pacts/*.json", "pact files to serve")
+	var states stringsFlag
+	flags.Var(&states, "state", "provider state to answer with when the request does not name one, can be repeated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	pacts, err := pactstub.Load(*pattern)
+	if err != nil {
+		return err
+	}
+
+	providerURLs := map[string]string{
+		providerProductService: c.ExternalURL().ProductAPI,
+		providerStockService:   c.ExternalURL().StockAPI,
+	}
+
+	logger := logrus.New()
+	errs := make(chan error, len(pacts))
+	var serving int
+	for _, pact := range pacts {
+		rawURL, ok := providerURLs[pact.Provider.Name]
+		if !ok {
+			logger.Warnf("no external url is configured for %s, its pact is not served", pact.Provider.Name)
+			continue
+		}
+
+		providerURL, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("invalid url of %s: %w", pact.Provider.Name, err)
+		}
+
+		addr := fmt.Sprintf(":%s", providerURL.Port())
+		handler := pactstub.NewServer(&pactstub.NewServerOpts{
+			Pact: pact, States: states, L: logger,
+		})
+
+		logger.Infof("serving %d interactions of %s on %s", len(pact.Interactions), pact.Provider.Name, addr)
+		serving++
+		go func() {
+			errs <- http.ListenAndServe(addr, handler)
+		}()
+	}
+
+	if serving == 0 {
+		return errors.New("no pact is served")
+	}
+
+	return <-errs
+}